@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/QuintenQVD0/PalworldServerConfigParser/palconfig"
+)
+
+// editOptions holds the flags shared by the get, set, unset and list commands
+type editOptions struct {
+	paths       *pathOptions
+	output      *string
+	showSecrets *bool
+}
+
+// addEditFlags registers the flags shared by the editing commands on flags.
+func addEditFlags(flags *flag.FlagSet) *editOptions {
+	return &editOptions{
+		paths:       addPathFlags(flags),
+		output:      flags.String("output", "text", "output format: text, or json for one JSON record per line"),
+		showSecrets: flags.Bool("show-secrets", false, "print the values of secret keys such as AdminPassword instead of masking them"),
+	}
+}
+
+// load parses the command line and loads PalWorldSettings.ini. It returns an exit code
+// other than exitOK when that fails, or when the command does not get nargs arguments.
+func (o *editOptions) load(flags *flag.FlagSet, args []string, nargs int, usage string) (*output, *palconfig.Config, int) {
+	flags.Parse(args)
+
+	out, err := newOutput(*o.output)
+	if err != nil {
+		fmt.Println(err)
+		return nil, nil, exitUsage
+	}
+	if flags.NArg() != nargs {
+		fmt.Printf("Usage: %s %s\n", flags.Name(), usage)
+		return nil, nil, exitUsage
+	}
+	if nargs > 0 && !palconfig.IsKnownKey(flags.Arg(0)) {
+		return nil, nil, out.fail(exitUsage, "Invalid key", fmt.Errorf("%w: %s", palconfig.ErrUnknownKey, flags.Arg(0)))
+	}
+
+	paths, code := o.paths.resolve(out)
+	if code != exitOK {
+		return nil, nil, code
+	}
+	config, code := loadSettings(out, paths.Settings)
+	return out, config, code
+}
+
+// loadSettings loads the PalWorldSettings.ini file at path, or returns the exit code of the failure.
+func loadSettings(out *output, path string) (*palconfig.Config, int) {
+	config, err := palconfig.Load(path)
+	if errors.Is(err, palconfig.ErrInvalidConfig) || os.IsNotExist(err) {
+		return nil, out.fail(exitMissingConfig, "Error loading INI file", err)
+	} else if err != nil {
+		return nil, out.fail(exitIOError, "Error reading INI file", err)
+	}
+	return config, exitOK
+}
+
+// display masks the value of secret keys unless -show-secrets is set
+func (o *editOptions) display(key, value string) string {
+	return displayValue(key, value, *o.showSecrets)
+}
+
+// get prints the value of a single key and returns the exit code.
+func get(args []string) int {
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	options := addEditFlags(flags)
+	out, config, code := options.load(flags, args, 1, "[flags] <key>")
+	if code != exitOK {
+		return code
+	}
+
+	key := flags.Arg(0)
+	value, ok := config.Get(key)
+	if !ok {
+		return out.fail(exitMissingConfig, "Key not found", fmt.Errorf("%w: %s", palconfig.ErrKeyNotFound, key))
+	}
+	out.event(eventKey, keyFields(config, key, options.display(key, value)), "%s\n", options.display(key, value))
+	return exitOK
+}
+
+// set validates and writes the value of a single key and returns the exit code.
+func set(args []string) int {
+	flags := flag.NewFlagSet("set", flag.ExitOnError)
+	options := addEditFlags(flags)
+	clamp := flags.Bool("clamp", false, "clamp numbers outside the allowed range of the key instead of rejecting them")
+	backups := flags.Int("backups", palconfig.DefaultKeepBackups, "number of timestamped backups of PalWorldSettings.ini to keep, 0 disables them")
+	out, config, code := options.load(flags, args, 2, "[flags] <key> <value>")
+	if code != exitOK {
+		return code
+	}
+	config.KeepBackups = *backups
+
+	key, value := flags.Arg(0), flags.Arg(1)
+	if *clamp && value != "" {
+		if clamped, ok, err := palconfig.ClampValue(key, value); err == nil && ok {
+			out.event(eventKeyClamped, fields{"key": key, "value": options.display(key, value), "clamped": options.display(key, clamped)},
+				"Clamping key: %s value: %s to %s\n", key, options.display(key, value), options.display(key, clamped))
+			value = clamped
+		}
+	}
+	return writeValue(out, options, config, key, value)
+}
+
+// unset resets a single key to its default value and returns the exit code.
+func unset(args []string) int {
+	flags := flag.NewFlagSet("unset", flag.ExitOnError)
+	options := addEditFlags(flags)
+	backups := flags.Int("backups", palconfig.DefaultKeepBackups, "number of timestamped backups of PalWorldSettings.ini to keep, 0 disables them")
+	out, config, code := options.load(flags, args, 1, "[flags] <key>")
+	if code != exitOK {
+		return code
+	}
+	config.KeepBackups = *backups
+
+	spec, _ := palconfig.LookupKey(flags.Arg(0))
+	return writeValue(out, options, config, spec.Name, spec.Default)
+}
+
+// writeValue sets key to value in config and saves it when the result is valid.
+func writeValue(out *output, options *editOptions, config *palconfig.Config, key, value string) int {
+	missing := !config.Has(key)
+	err := config.Set(key, value)
+	var validationErr *palconfig.ValidationError
+	if errors.As(err, &validationErr) {
+		out.event(eventValidationFailed, fields{"key": key, "value": options.display(key, value), "reason": validationErr.Reason},
+			"Validation failed for key: %s, value: %s (%s)\n", key, options.display(key, value), validationErr.Reason)
+		return exitValidation
+	} else if err != nil {
+		return out.fail(exitValidation, "Error setting key", err)
+	}
+
+	stored, _ := config.Get(key)
+	out.event(eventKeyUpdated, fields{"key": key, "value": options.display(key, stored), "added": missing},
+		"Updating key: %s with value: %s\n", key, options.display(key, stored))
+
+	if checkConstraints(out, config) > 0 {
+		out.event(eventWriteResult, fields{"path": config.Path(), "status": writeAborted, "exit_code": exitValidation},
+			"Not writing INI file because of invalid key combinations.\n")
+		return exitValidation
+	}
+	if err := config.Save(); err != nil {
+		return out.fail(exitIOError, "Error writing updated INI file", err)
+	}
+	out.event(eventWriteResult, fields{"path": config.Path(), "status": writeWritten, "exit_code": exitOK}, "INI file updated successfully.\n")
+	return exitOK
+}
+
+// list prints every key of the schema with its value in the INI file and returns the exit code.
+func list(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	options := addEditFlags(flags)
+	changed := flags.Bool("changed", false, "only list keys whose value differs from the default")
+	out, config, code := options.load(flags, args, 0, "[flags]")
+	if code != exitOK {
+		return code
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, spec := range palconfig.Schema {
+		key := spec.Name
+		value, _ := config.Get(key)
+		if *changed && (!config.Has(key) || !palconfig.ChangedFromDefault(key, value)) {
+			continue
+		}
+
+		shown := fmt.Sprintf("%q", options.display(key, value))
+		if !config.Has(key) {
+			shown = "(missing)"
+		}
+		if out.json {
+			out.event(eventKey, keyFields(config, key, options.display(key, value)), "")
+		} else {
+			fmt.Fprintf(table, "%s\t%s\n", key, shown)
+		}
+	}
+	table.Flush()
+	return exitOK
+}
+
+// keyFields returns the fields of a key event for key with the displayed value.
+func keyFields(config *palconfig.Config, key, value string) fields {
+	spec, _ := palconfig.LookupKey(key)
+	stored, _ := config.Get(key)
+	return fields{
+		"key":     key,
+		"env":     spec.EnvName(),
+		"value":   value,
+		"default": spec.Default,
+		"present": config.Has(key),
+		"changed": config.Has(key) && palconfig.ChangedFromDefault(key, stored),
+	}
+}
+
+// export prints the environment variables that reproduce PalWorldSettings.ini and returns the exit code.
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	pathFlags := addPathFlags(flags)
+	format := flags.String("format", string(palconfig.ExportDotenv), "output format: env, compose or kubernetes")
+	all := flags.Bool("all", false, "include values that are equal to the default")
+	name := flags.String("name", palconfig.DefaultExportName, "name of the Kubernetes ConfigMap and Secret")
+	outputFile := flags.String("o", "", "write the export to this file instead of printing it")
+	flags.Parse(args)
+
+	if !slices.Contains(palconfig.ExportFormats, palconfig.ExportFormat(*format)) {
+		fmt.Printf("Invalid export format: %s (expected env, compose or kubernetes)\n", *format)
+		return exitUsage
+	}
+	// The export itself goes to stdout, so errors are only ever text
+	out := &output{}
+
+	paths, code := pathFlags.resolve(out)
+	if code != exitOK {
+		return code
+	}
+	config, code := loadSettings(out, paths.Settings)
+	if code != exitOK {
+		return code
+	}
+
+	data, err := config.Export(palconfig.ExportOptions{Format: palconfig.ExportFormat(*format), All: *all, Name: *name})
+	if err != nil {
+		return out.fail(exitUsage, "Error exporting INI file", err)
+	}
+	if *outputFile == "" {
+		os.Stdout.Write(data)
+		return exitOK
+	}
+	// The export may hold passwords, so it is only readable by the owner
+	if err := palconfig.WriteFileAtomic(*outputFile, data, 0600); err != nil {
+		return out.fail(exitIOError, "Error writing file", err)
+	}
+	fmt.Printf("Exported %s written to: %s\n", *format, *outputFile)
+	return exitOK
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around every change
+const diffContext = 3
+
+// diffLine is a single line of an edit script
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns the changes between a and b in unified diff format,
+// or an empty string when they are equal.
+func unifiedDiff(aName, bName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+
+	script := editScript(splitLines(string(a)), splitLines(string(b)))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+
+	for start := 0; start < len(script); {
+		// Find the next change
+		for start < len(script) && script[start].op == ' ' {
+			start++
+		}
+		if start == len(script) {
+			break
+		}
+
+		// Extend the hunk while changes are closer than two contexts apart
+		hunkStart := max(start-diffContext, 0)
+		end := start
+		for i := start; i < len(script); i++ {
+			if script[i].op != ' ' {
+				end = i
+			} else if i-end > 2*diffContext {
+				break
+			}
+		}
+		hunkEnd := min(end+diffContext+1, len(script))
+
+		writeHunk(&out, script, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+// writeHunk writes script[from:to] with its @@ header.
+func writeHunk(out *strings.Builder, script []diffLine, from, to int) {
+	// Line numbers of the hunk start in both files
+	aLine, bLine := 1, 1
+	for _, line := range script[:from] {
+		if line.op != '+' {
+			aLine++
+		}
+		if line.op != '-' {
+			bLine++
+		}
+	}
+
+	aCount, bCount := 0, 0
+	for _, line := range script[from:to] {
+		if line.op != '+' {
+			aCount++
+		}
+		if line.op != '-' {
+			bCount++
+		}
+	}
+	if aCount == 0 {
+		aLine--
+	}
+	if bCount == 0 {
+		bLine--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+	for _, line := range script[from:to] {
+		out.WriteByte(line.op)
+		out.WriteString(strings.TrimSuffix(line.text, "\n"))
+		out.WriteByte('\n')
+		if !strings.HasSuffix(line.text, "\n") {
+			out.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+// editScript computes the shortest line edit script from a to b using the longest common subsequence.
+func editScript(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var script []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, diffLine{'-', a[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		script = append(script, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		script = append(script, diffLine{'+', b[j]})
+	}
+	return script
+}
+
+// splitLines splits s into lines that keep their line ending.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
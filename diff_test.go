@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// numbered returns a file with the lines 1 to n, with some lines replaced by changes.
+func numbered(n int, changes map[int]string) []byte {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := changes[i]; ok {
+			b.WriteString(line + "\n")
+		} else {
+			fmt.Fprintf(&b, "%d\n", i)
+		}
+	}
+	return []byte(b.String())
+}
+
+// hunkHeaders returns the @@ lines of a diff.
+func hunkHeaders(diff string) []string {
+	var headers []string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			headers = append(headers, line)
+		}
+	}
+	return headers
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"both empty", "", "", ""},
+		{
+			"single change",
+			string(numbered(20, nil)), string(numbered(20, map[int]string{10: "x"})),
+			"--- old\n+++ new\n@@ -7,7 +7,7 @@\n 7\n 8\n 9\n-10\n+x\n 11\n 12\n 13\n",
+		},
+		{
+			"change at the start",
+			"a\nb\nc\nd\ne\n", "x\nb\nc\nd\ne\n",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+x\n b\n c\n d\n",
+		},
+		{
+			"added to an empty file",
+			"", "a\n",
+			"--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			"removed everything",
+			"a\n", "",
+			"--- old\n+++ new\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			"no newline at end of file",
+			"a\nb", "a\nc",
+			"--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			"newline added at end of file",
+			"a", "a\n",
+			"--- old\n+++ new\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", []byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes map[int]string
+		want    []string
+	}{
+		{"far apart", map[int]string{3: "x", 17: "y"}, []string{"@@ -1,6 +1,6 @@", "@@ -14,7 +14,7 @@"}},
+		{"contexts touch", map[int]string{5: "x", 12: "y"}, []string{"@@ -2,14 +2,14 @@"}},
+		{"one line too far", map[int]string{5: "x", 13: "y"}, []string{"@@ -2,7 +2,7 @@", "@@ -10,7 +10,7 @@"}},
+		{"adjacent", map[int]string{10: "x", 11: "y"}, []string{"@@ -7,8 +7,8 @@"}},
+		{"last line", map[int]string{20: "x"}, []string{"@@ -17,4 +17,4 @@"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := unifiedDiff("old", "new", numbered(20, nil), numbered(20, tt.changes))
+			if got := hunkHeaders(diff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hunks = %q, want %q\n%s", got, tt.want, diff)
+			}
+		})
+	}
+}
+
+func TestEditScript(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "abc", "abc", "   "},
+		{"insert", "ac", "abc", " + "},
+		{"delete", "abc", "ac", " - "},
+		{"replace", "abc", "axc", " -+ "},
+		{"shifted", "abcd", "acde", " -  +"},
+		{"disjoint", "ab", "cd", "--++"},
+		{"from empty", "", "ab", "++"},
+		{"to empty", "ab", "", "--"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+			script := editScript(a, b)
+
+			var ops []byte
+			var gotA, gotB []string
+			for _, line := range script {
+				ops = append(ops, line.op)
+				if line.op != '+' {
+					gotA = append(gotA, line.text)
+				}
+				if line.op != '-' {
+					gotB = append(gotB, line.text)
+				}
+			}
+			if string(ops) != tt.want {
+				t.Errorf("ops = %q, want %q", ops, tt.want)
+			}
+			// Applying the script must give back both sides
+			if strings.Join(gotA, "") != tt.a || strings.Join(gotB, "") != tt.b {
+				t.Errorf("script gives %q and %q, want %q and %q", gotA, gotB, tt.a, tt.b)
+			}
+		})
+	}
+}
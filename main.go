@@ -1,525 +1,508 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"io"
+	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"runtime"
 	"strconv"
-	"strings"
+	"text/tabwriter"
+
+	"github.com/QuintenQVD0/PalworldServerConfigParser/palconfig"
 )
 
 // Version of the program
 const Version = "v1.0.24"
 
+// Exit codes of the program, so container entrypoints can tell failures apart
+const (
+	exitOK            = 0
+	exitPending       = 1 // dry run only: the INI file would change
+	exitUsage         = 2 // invalid command line, as used by the flag package
+	exitIOError       = 3 // a file could not be read, copied or written
+	exitMissingConfig = 4 // no usable PalWorldSettings.ini, no backup to roll back to, or get of a missing key
+	exitValidation    = 5 // invalid values or key combinations
+	exitUnsupportedOS = 6
+	exitPartial       = 7 // some values failed validation, the valid ones were written
+)
+
 func main() {
-	fmt.Println("Program Version:", Version)
-	// ValidationRules holds validation rules for environment variables
-	var ValidationRules = map[string]func(string) bool{
-		"Numeric": func(val string) bool {
-			// Numeric: Allows only positive numeric values (e.g., "123", "456")
-			num, err := strconv.Atoi(val)
-			return err == nil && num >= 0
-		},
-		"Floating": func(val string) bool {
-			// Floating: Allows only positive floating-point values (e.g., "3.14", "0.005")
-			_, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				return false
-			}
-			// Check if the value contains a decimal point
-			decimalPointIndex := strings.Index(val, ".")
-			if decimalPointIndex == -1 {
-				return false // No decimal point found
-			}
-			// Check if there are digits after the decimal point
-			return decimalPointIndex < len(val)-1
-		},
-		"TrueFalse": func(val string) bool {
-			// TrueFalse: Allows values "True" or "False"
-			return val == "True" || val == "False"
-		},
-		"String": func(val string) bool {
-			// String: Allows string values with spaces (e.g., "Hello World", "This is a string")
-			return true // No validation needed for string with spaces
-		},
-		"AlphaDash": func(val string) bool {
-			// AlphaDash: Allows only alphanumeric characters and dashes (e.g., "abc123", "test-123")
-			return regexp.MustCompile(`^[a-zA-Z0-9_-]+$`).MatchString(val)
-		},
-		"CrossplayPlatforms": func(val string) bool {
-			// CrossplayPlatforms: Allows platform lists like "Steam,Xbox,PS5,Mac" or single platforms
-			// Remove any existing parentheses and trim spaces
-			val = strings.Trim(val, "() ")
-			if val == "" {
-				return true // Empty is allowed (means no crossplay)
-			}
-			
-			// Split by comma and validate each platform
-			platforms := strings.Split(val, ",")
-			validPlatforms := map[string]bool{
-				"Steam": true,
-				"Xbox":  true,
-				"PS5":   true,
-				"Mac":   true,
-			}
-			
-			for _, platform := range platforms {
-				platform = strings.TrimSpace(platform)
-				if platform != "" && !validPlatforms[platform] {
-					return false // Invalid platform found
-				}
-			}
-			return true
-		},
-		// Add more validation rules as needed
-	}
-
-	// Read environment variables
-	envVars := map[string]string{
-		"Difficulty":                           "DIFFICULTY",
-		"DayTimeSpeedRate":                     "DAY_TIME_SPEED_RATE",
-		"NightTimeSpeedRate":                   "NIGHT_TIME_SPEED_RATE",
-		"ExpRate":                              "EXP_RATE",
-		"PalCaptureRate":                       "PAL_CAPTURE_RATE",
-		"PalSpawnNumRate":                      "PAL_SPAWN_NUM_RATE",
-		"PalDamageRateAttack":                  "PAL_DAMAGE_RATE_ATTACK",
-		"PalDamageRateDefense":                 "PAL_DAMAGE_RATE_DEFENSE",
-		"PlayerDamageRateAttack":               "PLAYER_DAMAGE_RATE_ATTACK",
-		"PlayerDamageRateDefense":              "PLAYER_DAMAGE_RATE_DEFENSE",
-		"PlayerStomachDecreaceRate":            "PLAYER_STOMACH_DECREACE_RATE",
-		"PlayerStaminaDecreaceRate":            "PLAYER_STAMINA_DECREACE_RATE",
-		"PlayerAutoHPRegeneRate":               "PLAYER_AUTO_HP_REGENE_RATE",
-		"PlayerAutoHpRegeneRateInSleep":        "PLAYER_AUTO_HP_REGENE_RATE_IN_SLEEP",
-		"PalStomachDecreaceRate":               "PAL_STOMACH_DECREACE_RATE",
-		"PalStaminaDecreaceRate":               "PAL_STAMINA_DECREACE_RATE",
-		"PalAutoHPRegeneRate":                  "PAL_AUTO_HP_REGENE_RATE",
-		"PalAutoHpRegeneRateInSleep":           "PAL_AUTO_HP_REGENE_RATE_IN_SLEEP",
-		"BuildObjectDamageRate":                "BUILD_OBJECT_DAMAGE_RATE",
-		"BuildObjectDeteriorationDamageRate":   "BUILD_OBJECT_DETERIORATION_DAMAGE_RATE",
-		"CollectionDropRate":                   "COLLECTION_DROP_RATE",
-		"CollectionObjectHpRate":               "COLLECTION_OBJECT_HP_RATE",
-		"CollectionObjectRespawnSpeedRate":     "COLLECTION_OBJECT_RESPAWN_SPEED_RATE",
-		"EnemyDropItemRate":                    "ENEMY_DROP_ITEM_RATE",
-		"DeathPenalty":                         "DEATH_PENALTY",
-		"bEnablePlayerToPlayerDamage":          "ENABLE_PLAYER_TO_PLAYER_DAMAGE",
-		"bEnableFriendlyFire":                  "ENABLE_FRIENDLY_FIRE",
-		"bEnableInvaderEnemy":                  "ENABLE_ENEMY",
-		"bActiveUNKO":                          "ACTIVE_UNKO",
-		"bEnableAimAssistPad":                  "ENABLE_AIM_ASSIST_PAD",
-		"bEnableAimAssistKeyboard":             "ENABLE_AIM_ASSIST_KEYBOARD",
-		"DropItemMaxNum":                       "DROP_ITEM_MAX_NUM",
-		"DropItemMaxNum_UNKO":                  "DROP_ITEM_MAX_NUM_UNKO",
-		"BaseCampMaxNum":                       "BASE_CAMP_MAX_NUM",
-		"BaseCampWorkerMaxNum":                 "BASE_CAMP_WORKER_MAX_NUM",
-		"DropItemAliveMaxHours":                "DROP_ITEM_ALIVE_MAX_HOURS",
-		"bAutoResetGuildNoOnlinePlayers":       "AUTO_RESET_GUILD_NO_ONLINE_PLAYERS",
-		"AutoResetGuildTimeNoOnlinePlayers":    "AUTO_RESET_GUILD_TIME_NO_ONLINE_PLAYERS",
-		"GuildPlayerMaxNum":                    "GUILD_PLAYER_MAX_NUM",
-		"BaseCampMaxNumInGuild":                "BASE_CAMP_MAX_NUM_IN_GUILD",
-		"PalEggDefaultHatchingTime":            "PAL_EGG_DEFAULT_HATCHING_TIME",
-		"WorkSpeedRate":                        "WORK_SPEED_RATE",
-		"bIsMultiplay":                         "IS_MULTIPLAY",
-		"bIsPvP":                               "IS_PVP",
-		"bCanPickupOtherGuildDeathPenaltyDrop": "CAN_PICKUP_OTHER_GUILD_DEATH_PENALTY_DROP",
-		"bEnableNonLoginPenalty":               "ENABLE_NON_LOGIN_PENALTY",
-		"bEnableFastTravel":                    "ENABLE_FAST_TRAVEL",
-		"bIsStartLocationSelectByMap":          "IS_START_LOCATION_SELECT_BY_MAP",
-		"bExistPlayerAfterLogout":              "EXIST_PLAYER_AFTER_LOGOUT",
-		"bEnableDefenseOtherGuildPlayer":       "ENABLE_DEFENSE_OTHER_GUILD_PLAYER",
-		"CoopPlayerMaxNum":                     "COOP_PLAYER_MAX_NUM",
-		"ServerPlayerMaxNum":                   "MAX_PLAYERS",
-		"ServerName":                           "SERVER_NAME",
-		"ServerDescription":                    "SERVER_DESCRIPTION",
-		"ServerPassword":                       "SERVER_PASSWORD",
-		"AdminPassword":                        "ADMIN_PASSWORD",
-		"PublicPort":                           "SERVER_PORT",
-		"RCONPort":                             "RCON_PORT",
-		"RCONEnabled":                          "RCON_ENABLE",
-		"bUseAuth":                             "USE_AUTH",
-		"BanListURL":                           "BAN_LIST_URL",
-		"Region":                               "SERVER_REGION",
-		"bShowPlayerList":                      "SHOW_PLAYER_LIST",
-		"RESTAPIEnabled":                       "REST_API_ENABLED",
-		"RESTAPIPort":                          "REST_API_PORT",
-		"bIsUseBackupSaveData":                 "USE_BACKUP_SAVE_DATA",
-		"LogFormatType":                        "LOG_FORMAT_TYPE",
-		"SupplyDropSpan":                       "SUPPLY_DROP_SPAN",
-		"ChatPostLimitPerMinute":               "CHAT_POST_LIMIT",
-		"bInvisibleOtherGuildBaseCampAreaFX":   "INVISIBLE_OTHER_GUILD_BASE",	
-		"AutoSaveSpan":                         "AUTO_SAVE_SPAN",
-		"RandomizerType":                       "RANDOMIZER_TYPE",				
-		"RandomizerSeed":                       "RANDOMIZER_SEED",
-		"BuildObjectHpRate":                    "BUILD_OBJECT_HP_RATE",
-		"bHardcore":                            "HARDCORE",
-		"bPalLost":                             "PAL_LOST",
-		"bBuildAreaLimit":                      "BUILD_AREA_LIMIT",
-		"ItemWeightRate":                       "ITEM_WEIGHT_RATE",
-		"EnablePredatorBossPal":                "ENABLE_PREDATOR_BOSS_PAL",
-		"MaxBuildingLimitNum":                  "MAX_BUILDING_LIMIT_NUM",
-		"ServerReplicatePawnCullDistance":      "SERVER_REPLICATE_PAWN_CULL_DISTANCE",
-		"bIsRandomizerPalLevelRandom":          "IS_RANDOMIZER_PAL_LEVEL_RANDOM",
-		"bAllowGlobalPalboxExport":             "ALLOW_GLOBAL_PALBOX_EXPORT",
-		"bAllowGlobalPalboxImport":             "ALLOW_GLOBAL_PALBOX_IMPORT",
-		"bCharacterRecreateInHardcore":         "CHARACTER_RECREATE_IN_HARDCORE",
-		"EquipmentDurabilityDamageRate":        "EQUIPMENT_DURABILITY_DAMAGE_RATE",
-		"ItemContainerForceMarkDirtyInterval":  "ITEM_CONTAINER_FORCE_MARK_DIRTY_INTERVAL",
-		"ItemCorruptionMultiplier":             "ITEM_CORRUPTION_MULTIPLIER",
-		"CrossplayPlatforms":                   "CROSSPLAY_PLATFORMS",
-		// Add other environment variables and corresponding INI keys here
-	}
-
-	// Assign value to "PublicIP" key using the function getIPAddressKey()
-	envVars["PublicIP"] = getIPAddressKey()
-
-	// Specify validation rules for each key
-	envVarsValidationRules := map[string]string{
-		"Difficulty":                           "String",    //Difficulty=None,
-		"DayTimeSpeedRate":                     "Floating",  //DayTimeSpeedRate=1.000000,
-		"NightTimeSpeedRate":                   "Floating",  //NightTimeSpeedRate=1.000000,
-		"ExpRate":                              "Floating",  //ExpRate=1.000000,
-		"PalCaptureRate":                       "Floating",  //PalCaptureRate=1.000000,
-		"PalSpawnNumRate":                      "Floating",  //PalSpawnNumRate=1.000000,
-		"PalDamageRateAttack":                  "Floating",  //PalDamageRateAttack=1.000000,
-		"PalDamageRateDefense":                 "Floating",  //PalDamageRateDefense=1.000000,
-		"PlayerDamageRateAttack":               "Floating",  //PlayerDamageRateAttack=1.000000,
-		"PlayerDamageRateDefense":              "Floating",  //PlayerDamageRateDefense=1.000000,
-		"PlayerStomachDecreaceRate":            "Floating",  //PlayerStomachDecreaceRate=1.000000,
-		"PlayerStaminaDecreaceRate":            "Floating",  //PlayerStaminaDecreaceRate=1.000000,
-		"PlayerAutoHPRegeneRate":               "Floating",  //PlayerAutoHPRegeneRate=1.000000,
-		"PlayerAutoHpRegeneRateInSleep":        "Floating",  //PlayerAutoHpRegeneRateInSleep=1.000000,
-		"PalStaminaDecreaceRate":               "Floating",  //PalStaminaDecreaceRate=1.000000,
-		"PalStomachDecreaceRate":               "Floating",  //PalStomachDecreaceRate=1.000000,
-		"PalAutoHPRegeneRate":                  "Floating",  //PalAutoHPRegeneRate=1.000000,
-		"PalAutoHpRegeneRateInSleep":           "Floating",  //PalAutoHpRegeneRateInSleep=1.000000,
-		"BuildObjectDamageRate":                "Floating",  //BuildObjectDamageRate=1.000000,
-		"BuildObjectDeteriorationDamageRate":   "Floating",  //BuildObjectDeteriorationDamageRate=1.000000,
-		"CollectionDropRate":                   "Floating",  //CollectionDropRate=1.000000,
-		"CollectionObjectHpRate":               "Floating",  //CollectionObjectHpRate=1.000000,
-		"CollectionObjectRespawnSpeedRate":     "Floating",  //CollectionObjectRespawnSpeedRate=1.000000,
-		"EnemyDropItemRate":                    "Floating",  //EnemyDropItemRate=1.000000,
-		"DeathPenalty":                         "String",    //DeathPenalty=All,
-		"bEnablePlayerToPlayerDamage":          "TrueFalse", //bEnablePlayerToPlayerDamage=False,
-		"bEnableFriendlyFire":                  "TrueFalse", //bEnableFriendlyFire=False,
-		"bEnableInvaderEnemy":                  "TrueFalse", //bEnableInvaderEnemy=True,
-		"bActiveUNKO":                          "TrueFalse", //bActiveUNKO=False,
-		"bEnableAimAssistPad":                  "TrueFalse", //bEnableAimAssistPad=True,
-		"bEnableAimAssistKeyboard":             "TrueFalse", //bEnableAimAssistKeyboard=False,
-		"DropItemMaxNum":                       "Numeric",   //DropItemMaxNum=3000,
-		"DropItemMaxNum_UNKO":                  "Numeric",   //DropItemMaxNum_UNKO=100,
-		"BaseCampMaxNum":                       "Numeric",   //BaseCampMaxNum=128,
-		"BaseCampWorkerMaxNum":                 "Numeric",   //BaseCampWorkerMaxNum=15,
-		"DropItemAliveMaxHours":                "Floating",  //DropItemAliveMaxHours=1.000000,
-		"AutoResetGuildTimeNoOnlinePlayers":    "Floating",  //AutoResetGuildTimeNoOnlinePlayers=72.000000,
-		"bAutoResetGuildNoOnlinePlayers":       "TrueFalse", //bAutoResetGuildNoOnlinePlayers=False,
-		"GuildPlayerMaxNum":                    "Numeric",   //GuildPlayerMaxNum=20,
-		"BaseCampMaxNumInGuild":                "Numeric",   //BaseCampMaxNumInGuild=3,
-		"PalEggDefaultHatchingTime":            "Floating",  //PalEggDefaultHatchingTime=72.000000,
-		"WorkSpeedRate":                        "Floating",  //WorkSpeedRate=1.000000,
-		"bIsMultiplay":                         "TrueFalse", //bIsMultiplay=False,
-		"bIsPvP":                               "TrueFalse", //bIsPvP=False,
-		"bCanPickupOtherGuildDeathPenaltyDrop": "TrueFalse", //bCanPickupOtherGuildDeathPenaltyDrop=False,
-		"bEnableNonLoginPenalty":               "TrueFalse", //bEnableNonLoginPenalty=True,
-		"bEnableFastTravel":                    "TrueFalse", //bEnableFastTravel=True,
-		"bIsStartLocationSelectByMap":          "TrueFalse", //bIsStartLocationSelectByMap=True,
-		"bExistPlayerAfterLogout":              "TrueFalse", //bExistPlayerAfterLogout=False,
-		"bEnableDefenseOtherGuildPlayer":       "TrueFalse", //bEnableDefenseOtherGuildPlayer=False,
-		"CoopPlayerMaxNum":                     "Numeric",   //CoopPlayerMaxNum=4,
-		"ServerPlayerMaxNum":                   "Numeric",   //ServerPlayerMaxNum=32,
-		"ServerName":                           "String",    //ServerName="Default Palworld Server",
-		"ServerDescription":                    "String",    //ServerDescription="",
-		"ServerPassword":                       "AlphaDash", //ServerPassword="",
-		"AdminPassword":                        "AlphaDash", //AdminPassword="",
-		"PublicIP":                             "String",    //PublicIP="",
-		"PublicPort":                           "Numeric",   //PublicPort=8211,
-		"RCONPort":                             "Numeric",   //RCONPort=25575,
-		"RCONEnabled":                          "TrueFalse", //RCONEnabled=False,
-		"bUseAuth":                             "TrueFalse", //bUseAuth=True,
-		"BanListURL":                           "String",    //BanListURL="https://api.palworldgame.com/api/banlist.txt"
-		"Region":                               "String",    //Region="",
-		"bShowPlayerList":                      "TrueFalse", //bShowPlayerList=False
-		"RESTAPIEnabled":                       "TrueFalse", //RESTAPIEnabled=False
-		"RESTAPIPort":                          "Numeric",   //RESTAPIPort=8212
-		"bIsUseBackupSaveData":                 "TrueFalse", //bIsUseBackupSaveData=True
-		"LogFormatType":                        "String",    //LogFormatType=Text
-		"SupplyDropSpan":                       "Numeric",   //SupplyDropSpan=180
-		"ChatPostLimitPerMinute":               "Numeric",   //ChatPostLimitPerMinute=10	
-		"bInvisibleOtherGuildBaseCampAreaFX":   "TrueFalse", //bInvisibleOtherGuildBaseCampAreaFX=False,		
-		"AutoSaveSpan":                         "Numeric",   //AutoSaveSpan=30,		
-		"RandomizerType":                       "String",    //RandomizerType=Node,		
-		"RandomizerSeed":                       "String",    //RandomizerSeed="",
-		"BuildObjectHpRate":                    "Floating",  //BuildObjectHpRate=1.000000,
-		"bHardcore":                         	"TrueFalse", //bHardcore=False,
-		"bPalLost":                         	"TrueFalse", //bPalLost=False,
-		"bBuildAreaLimit":                      "TrueFalse", //bBuildAreaLimit=False,
-		"ItemWeightRate":                       "Floating",  //ItemWeightRate=1.000000,
-		"EnablePredatorBossPal":                "TrueFalse", //EnablePredatorBossPal=True,
-		"MaxBuildingLimitNum":                  "Numeric",   //MaxBuildingLimitNum=0,
-		"ServerReplicatePawnCullDistance":      "Floating",  //ServerReplicatePawnCullDistance=15000.000000,
-		"bIsRandomizerPalLevelRandom":          "TrueFalse", //bIsRandomizerPalLevelRandom=False,
-		"bAllowGlobalPalboxExport":             "TrueFalse", //bAllowGlobalPalboxExport=True
-		"bAllowGlobalPalboxImport":             "TrueFalse", //bAllowGlobalPalboxImport=False
-		"bCharacterRecreateInHardcore":         "TrueFalse", //bCharacterRecreateInHardcore=False
-		"EquipmentDurabilityDamageRate":        "Floating",  //EquipmentDurabilityDamageRate=1.000000,
-		"ItemContainerForceMarkDirtyInterval":  "Floating",  //ItemContainerForceMarkDirtyInterval=1.000000
-		"ItemCorruptionMultiplier":             "Floating",  //ItemCorruptionMultiplier=1.000000
-		"CrossplayPlatforms":                   "CrossplayPlatforms", //CrossplayPlatforms=(Steam,Xbox,PS5,Mac)
-
-		// Add other keys as needed
-	}
-
-	// Specify keys for which quotes should be added
-	envVarsQuotes := map[string]bool{
-		"ServerName":        true,
-		"ServerPassword":    true,
-		"AdminPassword":     true,
-		"ServerDescription": true,
-		"BanListURL":        true,
-		"PublicIP":          true,
-		"RandomizerSeed":    true,
-		// Add other keys as needed
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "docs":
+			// Print the key table that is used in the README
+			fmt.Print(palconfig.MarkdownTable())
+			return
+		case "rollback":
+			os.Exit(rollback(os.Args[2:]))
+		case "defaults":
+			os.Exit(defaults(os.Args[2:]))
+		case "get":
+			os.Exit(get(os.Args[2:]))
+		case "set":
+			os.Exit(set(os.Args[2:]))
+		case "unset":
+			os.Exit(unset(os.Args[2:]))
+		case "list":
+			os.Exit(list(os.Args[2:]))
+		case "export":
+			os.Exit(export(os.Args[2:]))
+		}
+	}
+
+	os.Exit(apply(os.Args[1:]))
+}
+
+// pathOptions holds the command line flags that locate the INI files
+type pathOptions struct {
+	root     *string
+	settings *string
+	defaults *string
+}
+
+// addPathFlags registers the path flags on flags. Their defaults come from the environment.
+func addPathFlags(flags *flag.FlagSet) *pathOptions {
+	return &pathOptions{
+		root:     flags.String("root", os.Getenv("PALWORLD_SERVER_ROOT"), "server install root, discovered from common layouts when empty (env PALWORLD_SERVER_ROOT)"),
+		settings: flags.String("settings", os.Getenv("PALWORLD_SETTINGS_PATH"), "path of PalWorldSettings.ini, below the server root when empty (env PALWORLD_SETTINGS_PATH)"),
+		defaults: flags.String("defaults", os.Getenv("PALWORLD_DEFAULTS_PATH"), "path of DefaultPalWorldSettings.ini, in the server root when empty (env PALWORLD_DEFAULTS_PATH)"),
 	}
+}
+
+// resolve returns the INI paths, or an exit code when they can not be determined.
+func (o *pathOptions) resolve(out *output) (palconfig.Paths, int) {
 	// Determine the operating system
-	var osFolder string
-	switch runtime.GOOS {
-	case "windows":
-		osFolder = "WindowsServer"
-	case "linux":
-		// Check if the WINEPREFIX environment variable exists
-		if _, winePrefixExists := os.LookupEnv("WINEPREFIX"); winePrefixExists {
-			osFolder = "WindowsServer"
-		} else if _, err := exec.LookPath("proton"); err == nil {
-			osFolder = "WindowsServer"
-		} else {
-			osFolder = "LinuxServer"
-		}
-	default:
-		fmt.Println("Unsupported operating system")
-		return
+	osFolder, err := palconfig.PlatformFolder()
+	if err != nil && *o.settings == "" {
+		out.event(eventError, fields{"message": "Unsupported operating system", "exit_code": exitUnsupportedOS}, "Unsupported operating system\n")
+		return palconfig.Paths{}, exitUnsupportedOS
 	}
 
-	// Get the absolute path to the INI file
-	iniFilePath, err := filepath.Abs(fmt.Sprintf("Pal/Saved/Config/%s/PalWorldSettings.ini", osFolder))
+	// Get the absolute paths to the INI files
+	paths, err := palconfig.ResolvePaths(*o.root, *o.settings, *o.defaults, osFolder)
 	if err != nil {
-		fmt.Printf("Error getting absolute path: %v\n", err)
-		return
+		return palconfig.Paths{}, out.fail(exitIOError, "Error getting absolute path", err)
+	}
+	out.event(eventPaths, fields{"settings": paths.Settings, "defaults": paths.Defaults}, "")
+	return paths, exitOK
+}
+
+// rollback restores the newest backup of PalWorldSettings.ini and returns the exit code.
+func rollback(args []string) int {
+	flags := flag.NewFlagSet("rollback", flag.ExitOnError)
+	pathFlags := addPathFlags(flags)
+	outputFormat := flags.String("output", "text", "output format: text, or json for one JSON record per line")
+	flags.Parse(args)
+
+	out, err := newOutput(*outputFormat)
+	if err != nil {
+		fmt.Println(err)
+		return exitUsage
+	}
+
+	paths, code := pathFlags.resolve(out)
+	if code != exitOK {
+		return code
 	}
+	iniFilePath := paths.Settings
 
-	// Check if PalWorldSettings.ini exists
-	fileInfo, err := os.Stat(iniFilePath)
+	restored, err := palconfig.Rollback(iniFilePath)
+	if errors.Is(err, palconfig.ErrNoBackup) {
+		return out.fail(exitMissingConfig, "No backup of PalWorldSettings.ini found to roll back to", err)
+	} else if err != nil {
+		return out.fail(exitIOError, "Error restoring backup", err)
+	}
+	out.event(eventRollback, fields{"path": iniFilePath, "backup": restored}, "PalWorldSettings.ini restored from: %s\n", restored)
+	return exitOK
+}
+
+// defaults prints or writes the embedded DefaultPalWorldSettings.ini and returns the exit code.
+func defaults(args []string) int {
+	flags := flag.NewFlagSet("defaults", flag.ExitOnError)
+	output := flags.String("o", "", "write the template to this file instead of printing it")
+	flags.Parse(args)
+
+	if *output == "" {
+		os.Stdout.Write(palconfig.DefaultTemplate())
+		return exitOK
+	}
+
+	if err := palconfig.WriteFileAtomic(*output, palconfig.DefaultTemplate(), 0644); err != nil {
+		fmt.Printf("Error writing file: %v\n", err)
+		return exitIOError
+	}
+	fmt.Printf("Embedded DefaultPalWorldSettings.ini (%s) written to: %s\n", palconfig.DefaultTemplateOrigin, *output)
+	return exitOK
+}
+
+// apply updates PalWorldSettings.ini from the environment and returns the exit code.
+func apply(args []string) int {
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+	clamp := flags.Bool("clamp", false, "clamp numbers outside the allowed range of a key instead of rejecting them")
+	showSecrets := flags.Bool("show-secrets", false, "print the values of secret keys such as AdminPassword instead of masking them")
+	skipMissing := flags.Bool("skip-missing", false, "skip keys that are missing from the INI file instead of adding them")
+	strict := flags.Bool("strict", false, "abort without writing when any value fails validation")
+	backups := flags.Int("backups", palconfig.DefaultKeepBackups, "number of timestamped backups of PalWorldSettings.ini to keep, 0 disables them")
+	pathFlags := addPathFlags(flags)
+	dryRun := flags.Bool("dry-run", false, "print the pending changes as a table and a unified diff without writing anything")
+	reportFormat := flags.String("report", "text", "format of the summary report of every key: text, json or none")
+	reportFile := flags.String("report-file", "", "write the summary report to this file instead of printing it")
+	outputFormat := flags.String("output", "text", "output format: text, or json for one JSON record per line")
+	var envFiles []string
+	flags.Func("env-file", "read variables from this .env file, repeat it to layer several files (later files and the real environment win)", func(path string) error {
+		envFiles = append(envFiles, path)
+		return nil
+	})
+	flags.Parse(args)
+
+	out, err := newOutput(*outputFormat)
+	if err != nil {
+		fmt.Println(err)
+		return exitUsage
+	}
+	if *reportFormat != "text" && *reportFormat != "json" && *reportFormat != "none" {
+		fmt.Printf("Invalid report format: %s (expected text, json or none)\n", *reportFormat)
+		return exitUsage
+	}
+
+	out.event(eventVersion, fields{"version": Version}, "Program Version: %s\n", Version)
+
+	// Values are layered: the INI file, then the .env files in order, then the real environment
+	environment := &palconfig.Environment{}
+	for _, path := range envFiles {
+		if err := environment.AddDotenvFile(path); err != nil {
+			return out.fail(exitIOError, "Error reading .env file", err)
+		}
+		out.event(eventEnvFile, fields{"path": path}, "Loaded variables from: %s\n", path)
+	}
+	environment.AddProcess()
+
+	paths, code := pathFlags.resolve(out)
+	if code != exitOK {
+		return code
+	}
+	iniFilePath, defaultIniPath := paths.Settings, paths.Defaults
+
+	// Read the contents of the original INI file, which is kept for the dry run diff
+	copyDefaults := false
+	original, err := os.ReadFile(iniFilePath)
 	if os.IsNotExist(err) {
 		// PalWorldSettings.ini does not exist
-		// Check if DefaultPalWorldSettings.ini exists in the current directory
-		defaultIniPath := "DefaultPalWorldSettings.ini"
-		if _, err := os.Stat(defaultIniPath); !os.IsNotExist(err) {
-			// DefaultPalWorldSettings.ini exists, so move it to the desired location
-			newIniPath := fmt.Sprintf("Pal/Saved/Config/%s/PalWorldSettings.ini", osFolder)
-			err := copyFile(defaultIniPath, iniFilePath)
-			if err != nil {
-				fmt.Printf("Error copying file: %v\n", err)
-				return
-			}
-			fmt.Println("DefaultPalWorldSettings.ini copied to:", newIniPath)
-		} else {
-			fmt.Println("PalWorldSettings.ini not found and DefaultPalWorldSettings.ini does not exist in the current directory.")
-			return // No need to continue if PalWorldSettings.ini doesn't exist and DefaultPalWorldSettings.ini isn't found
-		}
-	} else if fileInfo.Size() == 0 || fileInfo.Size() < 1200 {
-		// PalWorldSettings.ini exists but is empty
+		// Copy DefaultPalWorldSettings.ini, or the embedded one, to the desired location
+		copyDefaults = true
+	} else if err != nil {
+		return out.fail(exitIOError, "Error reading INI file", err)
+	} else if warnings, err := palconfig.CheckStructure(original); err != nil {
+		// PalWorldSettings.ini exists but the server can not use it
 		// Copy the default INI file
-		defaultIniPath := "DefaultPalWorldSettings.ini"
-		newIniPath := fmt.Sprintf("Pal/Saved/Config/%s/PalWorldSettings.ini", osFolder)
-		err := copyFile(defaultIniPath, iniFilePath)
-		if err != nil {
-			fmt.Printf("Error copying file: %v\n", err)
-			return
-		}
-		fmt.Println("DefaultPalWorldSettings.ini copied to:", newIniPath)
+		out.event(eventConfigUnusable, fields{"path": iniFilePath, "error": err.Error()},
+			"PalWorldSettings.ini at %s is not usable: %v\n", iniFilePath, err)
+		copyDefaults = true
 	} else {
-		fmt.Println("PalWorldSettings.ini found at:", iniFilePath)
+		out.event(eventConfigFound, fields{"path": iniFilePath}, "PalWorldSettings.ini found at: %s\n", iniFilePath)
+		for _, warning := range warnings {
+			out.event(eventStructureWarning, fields{"path": iniFilePath, "message": warning.Error()}, "Warning: %v\n", warning)
+		}
 	}
 
-	// Read the contents of the original INI file
-	iniOpenFIle, err := os.Open(iniFilePath)
-	if err != nil {
-		fmt.Println("Error opening file:", err)
-		return
-	}
+	// The defaults are seeded in memory and written by the single Save at the end,
+	// so the only backup of this run is the file that was replaced
+	content := original
+	var copied fields
+	var source string
+	if copyDefaults {
+		embedded := false
+		source = "DefaultPalWorldSettings.ini"
+		content, err = os.ReadFile(defaultIniPath)
+		if os.IsNotExist(err) {
+			// Fall back to the template shipped in the binary
+			out.event(eventDefaultsMissing, fields{"path": defaultIniPath}, "DefaultPalWorldSettings.ini does not exist at: %s\n", defaultIniPath)
+			source = fmt.Sprintf("Embedded DefaultPalWorldSettings.ini (%s)", palconfig.DefaultTemplateOrigin)
+			embedded = true
+			content = palconfig.DefaultTemplate()
+		} else if err != nil {
+			return out.fail(exitIOError, "Error copying file", err)
+		}
+		copied = fields{"source": source, "embedded": embedded, "path": iniFilePath, "dry_run": *dryRun}
 
-	defer iniOpenFIle.Close()
+		// A fresh install has no Config folder until the server has been started once
+		configDir := filepath.Dir(iniFilePath)
+		_, err := os.Stat(configDir)
+		createDir := os.IsNotExist(err)
 
-	iniContent, err := io.ReadAll(iniOpenFIle)
+		if *dryRun {
+			if createDir {
+				out.event(eventDirectoryCreated, fields{"path": configDir, "dry_run": true}, "Would create directory: %s\n", configDir)
+			}
+			out.event(eventDefaultsCopied, copied, "Would copy %s to: %s\n", source, iniFilePath)
+		} else if createDir {
+			if err := os.MkdirAll(configDir, 0755); err != nil {
+				return out.fail(exitIOError, "Error creating directory", err)
+			}
+			out.event(eventDirectoryCreated, fields{"path": configDir, "dry_run": false}, "Created directory: %s\n", configDir)
+		}
+	}
+
+	// Parse the contents of the INI file
+	config, err := palconfig.Parse(iniFilePath, content)
 	if err != nil {
-		fmt.Printf("Error reading INI file: %v\n", err)
-		return
+		return out.fail(exitMissingConfig, "Error parsing INI file", err)
+	}
+	config.SkipMissing = *skipMissing
+	config.KeepBackups = *backups
+	if copyDefaults && *backups <= 0 {
+		// Keep the file that is about to be replaced, it may still hold settings,
+		// so it is backed up even when backups are disabled, without pruning older ones then
+		config.KeepBackups = math.MaxInt
+	}
+	if encoding := config.Encoding(); encoding != (palconfig.Encoding{}) {
+		out.event(eventEncoding, fields{"path": iniFilePath, "encoding": encoding.String()}, "Keeping file encoding: %s\n", encoding)
 	}
 
-	// Update values based on environment variables
-	for key, value := range envVars {
+	display := func(key, value string) string {
+		return displayValue(key, value, *showSecrets)
+	}
 
-		//val is the value that is in the enviroment variable, ok is true or false based of it exitis
-		//LookupEnv retrieves the value of the environment variable named by the key. If the variable is present in the environment the value (which may be empty) is returned and the boolean is true. Otherwise the returned value will be empty and the boolean will be false.
-		val, ok := os.LookupEnv(value)
+	// Update values based on environment variables, in schema order so every run logs the same way
+	validationFailures := 0
+	report := &palconfig.Report{Path: iniFilePath}
+	for _, spec := range palconfig.Schema {
+		key := spec.Name
+
+		// Look up the variable in the .env files and the real environment, the last one that sets it wins
+		envValue, ok, err := environment.LookupKey(spec)
+		if errors.Is(err, palconfig.ErrConflictingEnv) {
+			return out.fail(exitUsage, "Invalid environment for key "+key, err)
+		} else if err != nil {
+			return out.fail(exitIOError, "Error reading secret file for key "+key, err)
+		}
+		env, val, source := envValue.Name, envValue.Value, envValue.Source
+		result := palconfig.KeyResult{Key: key, Env: env, Source: source}
+		old, _ := config.Get(key)
+		result.Old = display(key, old)
+
+		// Where the value comes from when the environment does not replace it
+		kept := palconfig.SourceINI
+		if copyDefaults || !config.Has(key) {
+			kept = palconfig.SourceDefaults
+		}
 
-		// If the environment variable doesn't exist skip
+		// If the environment variable doesn't exist skip, the value stays the one of the INI file
 		if !ok {
+			result.Outcome = palconfig.OutcomeSkipped
+			result.Source = kept
+			report.Add(result)
 			continue
 		}
 
-		//The variable exitis but is empty, so it will fail the validation so just set it to empty
-		if ok && val == "" {
-			fmt.Printf("Updating empty key: %s\n", key)
-			setINIValue(&iniContent, key, "", envVarsQuotes[key])
+		// Clamp out of range numbers to the nearest bound when asked to
+		if *clamp && val != "" {
+			if clamped, ok, err := palconfig.ClampValue(key, val); err == nil && ok {
+				out.event(eventKeyClamped, fields{"key": key, "env": env, "value": display(key, val), "clamped": display(key, clamped)},
+					"Clamping key: %s value: %s to %s\n", key, display(key, val), display(key, clamped))
+				val = clamped
+				result.Clamped = true
+			}
 		}
 
-		//The Variable is set and it is not empty so validate and if true then set it in the file
-		if ok && val != "" {
-			// Check if there's a validation rule for the key
-			if ruleName, ok := envVarsValidationRules[key]; ok {
-				// Check if there's a validation function for the rule name
-				if rule, ok := ValidationRules[ruleName]; ok {
-					// Validate the value based on the rule
-					if !rule(val) {
-						fmt.Printf("Validation failed for key: %s, value: %s\n", key, val)
-						continue
-					}
-				} else {
-					fmt.Printf("No validation rule found for key: %s\n", key)
-				}
-			} else {
-				fmt.Printf("No validation rule specified for key: %s\n", key)
+		// Validate the value and update it in the INI file
+		missing := !config.Has(key)
+		err = config.Set(key, val)
+		stored, _ := config.Get(key)
+		result.Added = missing && err == nil
+		result.New = display(key, stored)
+		updated := fields{"key": key, "env": env, "source": source, "value": display(key, stored), "added": missing}
+
+		var validationErr *palconfig.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			out.event(eventValidationFailed, fields{"key": key, "env": env, "source": source, "value": display(key, val), "reason": validationErr.Reason},
+				"Validation failed for key: %s, value: %s (%s)\n", key, display(key, val), validationErr.Reason)
+			validationFailures++
+			result.Outcome, result.New, result.Reason = palconfig.OutcomeRejected, display(key, val), validationErr.Reason
+			result.Source, result.RejectedSource = kept, source
+		case errors.Is(err, palconfig.ErrKeyNotFound):
+			out.event(eventKeyNotFound, fields{"key": key, "env": env}, "Key not found: %s\n", key)
+			result.Outcome = palconfig.OutcomeNotFound
+			result.Source, result.RejectedSource = kept, source
+		case err != nil:
+			out.event(eventValidationFailed, fields{"key": key, "env": env, "source": source, "value": display(key, val), "reason": err.Error()}, "%v\n", err)
+			result.Outcome, result.New, result.Reason = palconfig.OutcomeRejected, display(key, val), err.Error()
+			result.Source, result.RejectedSource = kept, source
+		case val == "":
+			//The variable exitis but is empty, so it will fail the validation so just set it to empty
+			out.event(eventKeyCleared, fields{"key": key, "env": env, "source": source, "added": missing}, "Updating empty key: %s\n", key)
+			result.Outcome = palconfig.OutcomeCleared
+			if !missing && old == "" {
+				result.Outcome = palconfig.OutcomeUnchanged
 			}
+		case missing:
+			out.event(eventKeyUpdated, updated, "Adding missing key: %s with value: %s\n", key, display(key, stored))
+			result.Outcome = palconfig.OutcomeUpdated
+		case stored == old:
+			result.Outcome = palconfig.OutcomeUnchanged
+		default:
+			out.event(eventKeyUpdated, updated, "Updating key: %s with value: %s\n", key, display(key, stored))
+			result.Outcome = palconfig.OutcomeUpdated
+		}
+		report.Add(result)
+	}
 
-			// Update the value in the INI file
-			fmt.Printf("Updating key: %s with value: %s\n", key, val)
-			setINIValue(&iniContent, key, val, envVarsQuotes[key])
+	// Check the merged config for combinations of keys that do not work together
+	constraintErrors := checkConstraints(out, config)
 
+	// Summarize what happened to every key. In JSON mode it is a record of its own
+	// unless it goes to a file.
+	if out.json && *reportFile == "" {
+		if *reportFormat != "none" {
+			out.event(eventReport, fields{"report": report}, "")
 		}
+	} else if err := printReport(report, *reportFormat, *reportFile); err != nil {
+		return out.fail(exitIOError, "Error writing report", err)
 	}
 
-	// Write the updated contents back to the INI file
-	err = os.WriteFile(iniFilePath, iniContent, 0644)
-	if err != nil {
-		fmt.Printf("Error writing updated INI file: %v\n", err)
-		return
+	// Show what would be written without touching the disk
+	if *dryRun {
+		printPendingChanges(out, iniFilePath, original, config, *showSecrets)
 	}
 
-	fmt.Println("INI file updated successfully.")
-}
+	changed := string(original) != string(config.Bytes())
+	result := func(status, reason string, code int) int {
+		out.event(eventWriteResult, fields{"path": iniFilePath, "status": status, "changed": changed, "reason": reason, "exit_code": code}, "%s\n", reason)
+		return code
+	}
 
-// setINIValue updates the value for the specified key in the INI content.
-func setINIValue(content *[]byte, key, value string, addQuotes bool) {
-	// Convert content to string for easy manipulation
-	contentStr := string(*content)
-
-	// Create the search string for the key
-	searchStr := fmt.Sprintf("%s=", key)
-
-	// Find the position of the key in the content
-	pos := strings.Index(contentStr, searchStr)
-	if pos == -1 {
-		// Key not found
-		fmt.Printf("Key not found: %s\n", key)
-		return
-	}
-
-	// Line 340-364 is chatgpt generated but seems to work just fine
-	var endPos int
-
-	if key == "CrossplayPlatforms" {
-		// CrossplayPlatforms' value is itself a parenthesized, comma-separated
-		// list, e.g. (Steam,Xbox,PS5,Mac). The generic "first comma or paren"
-		// logic below would stop at the first comma INSIDE that list instead
-		// of the closing ")" that actually ends the field, corrupting the
-		// value. So for this key we always end at the matching closing paren.
-		closeParen := strings.Index(contentStr[pos:], ")")
-		if closeParen == -1 {
-			endPos = len(contentStr)
-		} else {
-			// +1 to include the ")" itself so it gets replaced/removed and
-			// not duplicated when we re-add parentheses around the new value.
-			endPos = closeParen + 1
-		}
-	} else {
-		// normal case, key=value,
-		endPos_1 := strings.Index(contentStr[pos:], ",")
-		// Edge case as the last key has no ending ,
-		endPos_2 := strings.Index(contentStr[pos:], ")")
-
-		// Check if both endPos_1 and endPos_2 are -1, indicating neither comma nor closing parenthesis was found
-		if endPos_1 == -1 && endPos_2 == -1 {
-			// Set endPos to the end of the string
-			endPos = len(contentStr)
-		} else {
-			// If either endPos_1 or endPos_2 is -1, replace it with a large value
-			if endPos_1 == -1 {
-				endPos_1 = len(contentStr) + 1
-			}
-			if endPos_2 == -1 {
-				endPos_2 = len(contentStr) + 1
-			}
+	if constraintErrors > 0 {
+		return result(writeAborted, fmt.Sprintf("Not writing INI file because of %d invalid key combination(s).", constraintErrors), exitValidation)
+	}
+	if *strict && validationFailures > 0 {
+		return result(writeAborted, fmt.Sprintf("Not writing INI file because %d value(s) failed validation in strict mode.", validationFailures), exitValidation)
+	}
 
-			// Choose the minimum of endPos_1 and endPos_2
-			if endPos_1 <= endPos_2 {
-				endPos = endPos_1
-			} else {
-				endPos = endPos_2
-			}
+	if *dryRun {
+		if !changed {
+			return result(writeDryRun, "Dry run: no changes pending.", exitOK)
 		}
+		return result(writeDryRun, "Dry run: changes pending, nothing was written.", exitPending)
 	}
 
-	// Special handling for CrossplayPlatforms - add parentheses
-	if key == "CrossplayPlatforms" && value != "" {
-		// Remove any existing parentheses and trim
-		value = strings.Trim(value, "() ")
-		if value != "" {
-			value = fmt.Sprintf(`(%s)`, value)
-		}
+	// Write the updated contents back to the INI file
+	backupPath, err := config.SaveWithBackup()
+	if err != nil {
+		return out.fail(exitIOError, "Error writing updated INI file", err)
 	}
-	// If addQuotes is true and the key requires quotes, add quotes around the value
-	if addQuotes {
-		value = fmt.Sprintf(`"%s"`, value)
+	if backupPath != "" {
+		out.event(eventBackupCreated, fields{"path": iniFilePath, "backup": backupPath}, "PalWorldSettings.ini backed up to: %s\n", backupPath)
+	}
+	if copyDefaults {
+		out.event(eventDefaultsCopied, copied, "%s copied to: %s\n", source, iniFilePath)
 	}
 
-	// Calculate the positions in the byte slice
-	start := pos + len(searchStr)
-	end := pos + endPos
-
-	// Ensure the end position is within the slice bounds
-	if end > len(*content) {
-		end = len(*content)
+	if validationFailures > 0 {
+		// The server would start with the old values of the rejected keys
+		return result(writeWritten, fmt.Sprintf("INI file updated, but %d value(s) failed validation and were skipped.", validationFailures), exitPartial)
 	}
+	return result(writeWritten, "INI file updated successfully.", exitOK)
+}
 
-	// Update the content slice in place
-	*content = append((*content)[:start], append([]byte(value), (*content)[end:]...)...)
+// checkConstraints prints the findings of the cross-field constraints on config
+// and returns the number of errors among them.
+func checkConstraints(out *output, config *palconfig.Config) int {
+	errs := 0
+	for _, finding := range config.CheckConstraints() {
+		record := fields{"severity": finding.Severity, "keys": finding.Keys, "message": finding.Message}
+		if finding.Severity == palconfig.SeverityError {
+			errs++
+			out.event(eventConstraint, record, "Error: %s\n", finding.Message)
+		} else {
+			out.event(eventConstraint, record, "Warning: %s\n", finding.Message)
+		}
+	}
+	return errs
 }
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	srcOpenFile, err := os.Open(src)
+// printReport prints report in format, or writes it to path when it is not empty.
+func printReport(report *palconfig.Report, format, path string) error {
+	var data []byte
+	switch format {
+	case "none":
+		return nil
+	case "json":
+		var err error
+		if data, err = report.JSON(); err != nil {
+			return err
+		}
+	default:
+		data = []byte(report.Text())
+	}
 
-	if err != nil {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
 		return err
 	}
-	defer srcOpenFile.Close()
+	return palconfig.WriteFileAtomic(path, data, 0644)
+}
 
-	data, err := io.ReadAll(srcOpenFile)
-	if err != nil {
-		return err
+// displayValue masks the value of secret keys unless showSecrets is set
+func displayValue(key, value string, showSecrets bool) string {
+	if showSecrets {
+		return value
 	}
-	return os.WriteFile(dst, data, 0644)
+	return palconfig.Redact(key, value)
 }
 
-func getIPAddressKey() string {
-	// Check if PUBLIC_IP environment variable exists and is not empty
-	val, ok := os.LookupEnv("PUBLIC_IP")
-	if ok && val != "" {
-		return "PUBLIC_IP"
+// printPendingChanges prints a per-key table and a unified diff of the changes
+// between the original file content and config.
+func printPendingChanges(out *output, path string, original []byte, config *palconfig.Config, showSecrets bool) {
+	before, err := palconfig.Parse(path, original)
+	if err != nil {
+		before = nil // Missing or unusable file, every key is new
 	}
 
-	// Fallback to SERVER_IP if PUBLIC_IP is empty or does not exist
-	return "SERVER_IP"
-}
\ No newline at end of file
+	changes := palconfig.Changes(before, config)
+	if out.json {
+		for _, change := range changes {
+			out.event(eventPendingChange, fields{
+				"key":     change.Key,
+				"old":     displayValue(change.Key, change.Old, showSecrets),
+				"new":     displayValue(change.Key, change.New, showSecrets),
+				"added":   change.Added,
+				"removed": change.Removed,
+			}, "")
+		}
+	} else if len(changes) > 0 {
+		fmt.Println("Pending changes:")
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "  KEY\tOLD\t\tNEW")
+		for _, change := range changes {
+			oldValue := strconv.Quote(displayValue(change.Key, change.Old, showSecrets))
+			newValue := strconv.Quote(displayValue(change.Key, change.New, showSecrets))
+			if change.Added {
+				oldValue = "(missing)"
+			}
+			if change.Removed {
+				newValue = "(missing)"
+			}
+			fmt.Fprintf(table, "  %s\t%s\t→\t%s\n", change.Key, oldValue, newValue)
+		}
+		table.Flush()
+	}
+
+	// Diff the redacted renderings so secrets do not leak through the changed lines
+	oldContent, newContent := original, config.Text()
+	if before != nil {
+		oldContent = before.Text()
+	}
+	if !showSecrets {
+		// An unparsable original can not be redacted, so it is left out of the diff
+		oldContent = nil
+		if before != nil {
+			oldContent = before.RedactedBytes()
+		}
+		newContent = config.RedactedBytes()
+	}
+	diff := unifiedDiff(path, path, oldContent, newContent)
+	out.event(eventDiff, fields{"path": path, "diff": diff}, "%s", diff)
+}
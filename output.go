@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+)
+
+// Event types of the JSON Lines output. Panels match on these names and on the
+// field names of their records, so they must not change.
+const (
+	eventVersion          = "version"           // version
+	eventPaths            = "paths"             // settings, defaults
+	eventEnvFile          = "env_file"          // path
+	eventConfigFound      = "config_found"      // path
+	eventConfigUnusable   = "config_unusable"   // path, error
+	eventStructureWarning = "structure_warning" // path, message
+	eventDefaultsMissing  = "defaults_missing"  // path
+	eventDirectoryCreated = "directory_created" // path, dry_run
+	eventBackupCreated    = "backup_created"    // path, backup
+	eventDefaultsCopied   = "defaults_copied"   // source, embedded, path, dry_run
+	eventEncoding         = "encoding"          // path, encoding
+	eventKeyClamped       = "key_clamped"       // key, env, value, clamped
+	eventKeyUpdated       = "key_updated"       // key, env, source, value, added
+	eventKeyCleared       = "key_cleared"       // key, env, source, added
+	eventValidationFailed = "validation_failed" // key, env, source, value, reason
+	eventKeyNotFound      = "key_not_found"     // key, env
+	eventConstraint       = "constraint"        // severity, keys, message
+	eventReport           = "report"            // report
+	eventPendingChange    = "pending_change"    // key, old, new, added, removed
+	eventDiff             = "diff"              // path, diff
+	eventWriteResult      = "write_result"      // path, status, changed, reason, exit_code
+	eventRollback         = "rollback"          // path, backup
+	eventKey              = "key"               // key, env, value, default, present, changed
+	eventError            = "error"             // message, error, exit_code
+)
+
+// Statuses of the write_result event
+const (
+	writeWritten = "written"
+	writeDryRun  = "dry_run"
+	writeAborted = "aborted"
+)
+
+// fields are the data of an event, besides its type
+type fields map[string]any
+
+// output prints the events of a run either as text or as JSON Lines on stdout.
+type output struct {
+	json bool
+}
+
+// newOutput returns the output for format, which is text or json.
+func newOutput(format string) (*output, error) {
+	switch format {
+	case "text":
+		return &output{}, nil
+	case "json":
+		return &output{json: true}, nil
+	}
+	return nil, fmt.Errorf("invalid output format: %s (expected text or json)", format)
+}
+
+// event prints a record of type event with f in JSON mode, and format with args otherwise.
+func (o *output) event(event string, f fields, format string, args ...any) {
+	if !o.json {
+		fmt.Printf(format, args...)
+		return
+	}
+
+	record := make(map[string]any, len(f)+1)
+	maps.Copy(record, f)
+	record["event"] = event
+	data, err := json.Marshal(record)
+	if err != nil {
+		data, _ = json.Marshal(fields{"event": eventError, "message": "Error encoding event " + event, "error": err.Error()})
+	}
+	os.Stdout.Write(append(data, '\n'))
+}
+
+// fail prints message and err as an error event and returns code, the exit code it causes.
+func (o *output) fail(code int, message string, err error) int {
+	o.event(eventError, fields{"message": message, "error": err.Error(), "exit_code": code}, "%s: %v\n", message, err)
+	return code
+}
@@ -0,0 +1,218 @@
+package palconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultKeepBackups is the number of backups kept next to the settings file
+const DefaultKeepBackups = 5
+
+// backupTimeFormat sorts lexically in chronological order
+const backupTimeFormat = "20060102-150405.000"
+
+// backupSuffix ends the name of every backup file
+const backupSuffix = ".bak"
+
+// ErrNoBackup is returned by Rollback when there is no backup to restore
+var ErrNoBackup = errors.New("no backup found")
+
+// WriteFileAtomic writes data to a temporary file in the directory of path,
+// syncs it to disk and renames it over path, so readers never see a partial file.
+// An existing file keeps its permissions, a new one is created with perm.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// Remove the temporary file on any failure, this is a no-op after the rename
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename survives a crash. Not every platform
+	// supports this, so errors are ignored.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// BackupFile copies the file at path to a timestamped backup next to it and
+// removes the oldest backups so that at most keep remain. It does nothing when
+// keep is zero or the file does not exist, and returns the backup path otherwise.
+// The backup gets the permissions of the file, since it holds the same passwords.
+func BackupFile(path string, keep int) (string, error) {
+	if keep <= 0 {
+		return "", nil
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath, err := createBackup(path, data, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		return backupPath, err
+	}
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return backupPath, err
+		}
+		backups = backups[1:]
+	}
+	return backupPath, nil
+}
+
+// createBackup writes data to a new backup of path with perm. A backup made in the
+// same millisecond as an existing one gets a sequence number instead of replacing it.
+func createBackup(path string, data []byte, perm os.FileMode) (string, error) {
+	stamp := time.Now().Format(backupTimeFormat)
+	for seq := 0; ; seq++ {
+		backupPath := fmt.Sprintf("%s.%s%s", path, stamp, backupSuffix)
+		if seq > 0 {
+			backupPath = fmt.Sprintf("%s.%s-%d%s", path, stamp, seq, backupSuffix)
+		}
+
+		file, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		_, err = file.Write(data)
+		if err == nil {
+			err = file.Sync()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			// The umask may have dropped bits of perm on creation
+			err = os.Chmod(backupPath, perm)
+		}
+		if err != nil {
+			os.Remove(backupPath)
+			return "", err
+		}
+		return backupPath, nil
+	}
+}
+
+// backupFile is a backup found by Backups
+type backupFile struct {
+	path  string
+	stamp string
+	seq   int
+}
+
+// Backups returns the backups of the file at path, oldest first.
+func Backups(path string) ([]string, error) {
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(path) + "."
+	var found []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), backupSuffix)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		// An optional "-<n>" after the timestamp orders backups made in the same millisecond
+		stamp, rest := stamp[:len(backupTimeFormat)], stamp[len(backupTimeFormat):]
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		seq := 0
+		if rest != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(rest, "-"))
+			if err != nil || rest[0] != '-' || n <= 0 {
+				continue
+			}
+			seq = n
+		}
+		found = append(found, backupFile{path: filepath.Join(dir, name), stamp: stamp, seq: seq})
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].stamp != found[j].stamp {
+			return found[i].stamp < found[j].stamp
+		}
+		return found[i].seq < found[j].seq
+	})
+	backups := make([]string, len(found))
+	for i, backup := range found {
+		backups[i] = backup.path
+	}
+	return backups, nil
+}
+
+// Rollback restores the newest backup of the file at path and removes that backup,
+// so calling it again goes one version further back. It returns the restored backup.
+func Rollback(path string) (string, error) {
+	backups, err := Backups(path)
+	if err != nil {
+		return "", err
+	}
+	if len(backups) == 0 {
+		return "", fmt.Errorf("%w for %s", ErrNoBackup, path)
+	}
+
+	newest := backups[len(backups)-1]
+	data, err := os.ReadFile(newest)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
+		return "", err
+	}
+	return newest, os.Remove(newest)
+}
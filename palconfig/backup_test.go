@@ -0,0 +1,201 @@
+package palconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readFile returns the content of path or fails the test.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// writeVersions writes the versions to path one after the other, backing up each
+// previous version with BackupFile.
+func writeVersions(t *testing.T, path string, keep int, versions ...string) {
+	t.Helper()
+	for _, version := range versions {
+		if _, err := BackupFile(path, keep); err != nil {
+			t.Fatalf("BackupFile: %v", err)
+		}
+		if err := WriteFileAtomic(path, []byte(version), 0600); err != nil {
+			t.Fatalf("WriteFileAtomic: %v", err)
+		}
+	}
+}
+
+func TestBackupFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "PalWorldSettings.ini")
+	var versions []string
+	for i := 1; i <= 8; i++ {
+		versions = append(versions, fmt.Sprintf("version %d", i))
+	}
+	// Most of these land in the same millisecond and get sequence numbers
+	writeVersions(t, path, 3, versions...)
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, backup := range backups {
+		got = append(got, readFile(t, backup))
+	}
+	want := []string{"version 5", "version 6", "version 7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backups hold %q, want %q", got, want)
+	}
+}
+
+func TestBackupFileSkips(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "PalWorldSettings.ini")
+
+	if backup, err := BackupFile(path, DefaultKeepBackups); err != nil || backup != "" {
+		t.Errorf("BackupFile of a missing file = %q, %v", backup, err)
+	}
+
+	if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if backup, err := BackupFile(path, 0); err != nil || backup != "" {
+		t.Errorf("BackupFile with keep 0 = %q, %v", backup, err)
+	}
+	if backups, _ := Backups(path); len(backups) != 0 {
+		t.Errorf("backups were created: %q", backups)
+	}
+}
+
+func TestBackupFileKeepsPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "PalWorldSettings.ini")
+	if err := os.WriteFile(path, []byte("AdminPassword=secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := BackupFile(path, DefaultKeepBackups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("backup permissions = %v, want %v", perm, os.FileMode(0600))
+	}
+}
+
+func TestCreateBackupSequence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "PalWorldSettings.ini")
+
+	// Keep creating backups until two share a timestamp
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		backup, err := createBackup(path, []byte(fmt.Sprint(i)), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[backup] {
+			t.Fatalf("%s was created twice", backup)
+		}
+		seen[backup] = true
+		if readFile(t, backup) != fmt.Sprint(i) {
+			t.Fatalf("%s was overwritten", backup)
+		}
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 200 {
+		t.Fatalf("Backups found %d backups, want 200", len(backups))
+	}
+	for i, backup := range backups {
+		if got := readFile(t, backup); got != fmt.Sprint(i) {
+			t.Fatalf("backup %d holds %q, backups are out of order", i, got)
+		}
+	}
+}
+
+func TestBackupsOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "PalWorldSettings.ini")
+
+	names := []string{
+		"PalWorldSettings.ini.20240102-030405.000-10.bak",
+		"PalWorldSettings.ini.20240102-030405.000.bak",
+		"PalWorldSettings.ini.20240102-030405.001.bak",
+		"PalWorldSettings.ini.20240102-030405.000-2.bak",
+		"PalWorldSettings.ini.20231231-235959.999.bak",
+		// Not backups of path
+		"PalWorldSettings.ini.20240102-030405.000-0.bak",
+		"PalWorldSettings.ini.20240102-030405.000-x.bak",
+		"PalWorldSettings.ini.20240102-030405.000_1.bak",
+		"PalWorldSettings.ini.latest.bak",
+		"PalWorldSettings.ini.20240102-030405.000",
+		"Other.ini.20240102-030405.000.bak",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "PalWorldSettings.ini.20240103-030405.000.bak"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, backup := range backups {
+		got = append(got, filepath.Base(backup))
+	}
+	want := []string{
+		"PalWorldSettings.ini.20231231-235959.999.bak",
+		"PalWorldSettings.ini.20240102-030405.000.bak",
+		"PalWorldSettings.ini.20240102-030405.000-2.bak",
+		"PalWorldSettings.ini.20240102-030405.000-10.bak",
+		"PalWorldSettings.ini.20240102-030405.001.bak",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Backups = %q, want %q", got, want)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "PalWorldSettings.ini")
+	writeVersions(t, path, DefaultKeepBackups, "version 1", "version 2", "version 3", "version 4")
+
+	// Every rollback goes one version further back
+	for _, want := range []string{"version 3", "version 2", "version 1"} {
+		backup, err := Rollback(path)
+		if err != nil {
+			t.Fatalf("Rollback: %v", err)
+		}
+		if got := readFile(t, path); got != want {
+			t.Errorf("Rollback restored %q, want %q", got, want)
+		}
+		if _, err := os.Stat(backup); !os.IsNotExist(err) {
+			t.Errorf("restored backup %s was not removed", backup)
+		}
+	}
+
+	if _, err := Rollback(path); !errors.Is(err, ErrNoBackup) {
+		t.Errorf("Rollback without backups = %v, want %v", err, ErrNoBackup)
+	}
+	if got := readFile(t, path); got != "version 1" {
+		t.Errorf("failed Rollback changed the file to %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+package palconfig
+
+// Change describes how the value of a key differs between two configs.
+type Change struct {
+	Key     string
+	Old     string
+	New     string
+	Added   bool // the key is missing from the old config
+	Removed bool // the key is missing from the new config
+}
+
+// Changes lists the keys whose value differs between before and after, in the
+// order they appear in after. A nil before is treated as an empty config.
+func Changes(before, after *Config) []Change {
+	var changes []Change
+	seen := make(map[string]bool)
+	for _, entry := range after.settings.entries {
+		key := entry.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		newValue, _ := after.Get(key)
+		if before == nil || !before.Has(key) {
+			changes = append(changes, Change{Key: key, New: newValue, Added: true})
+			continue
+		}
+		oldValue, _ := before.Get(key)
+		if oldValue != newValue {
+			changes = append(changes, Change{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+
+	if before != nil {
+		for _, entry := range before.settings.entries {
+			key := entry.Key()
+			if seen[key] || after.Has(key) {
+				continue
+			}
+			seen[key] = true
+			oldValue, _ := before.Get(key)
+			changes = append(changes, Change{Key: key, Old: oldValue, Removed: true})
+		}
+	}
+	return changes
+}
+
+// ChangedFromDefault reports whether value differs from the default of key. Both are
+// compared in canonical form, so "2" equals "2.000000" for a Floating key.
+func ChangedFromDefault(key, value string) bool {
+	spec, ok := LookupKey(key)
+	if !ok {
+		return true
+	}
+	return canonicalValue(key, value) != canonicalValue(key, spec.Default)
+}
+
+// canonicalValue returns value as Set would write it, or unchanged when it is empty or invalid.
+func canonicalValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if canonical, err := ValidateValue(key, value); err == nil {
+		return canonical
+	}
+	return value
+}
@@ -0,0 +1,260 @@
+package palconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownKey is returned for keys this package has no rules for
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrInvalidConfig is returned when the content has no usable OptionSettings line
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrKeyNotFound is returned when a known key is missing from the OptionSettings line and SkipMissing is set
+	ErrKeyNotFound = errors.New("key not found")
+)
+
+// ValidationError reports a value that does not satisfy the rule of its key.
+// Error masks the value of secret keys, Value holds it unmasked.
+type ValidationError struct {
+	Key    string
+	Value  string
+	Rule   ValueType
+	Reason string
+	Range  *Range // set when the value parsed but lies outside the allowed range
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for key: %s, value: %s: %s", e.Key, Redact(e.Key, e.Value), e.Reason)
+}
+
+// Config is a loaded PalWorldSettings.ini file.
+type Config struct {
+	// KeepBackups is the number of timestamped backups Save keeps next to the file.
+	// Zero disables backups.
+	KeepBackups int
+
+	// SkipMissing makes Set return ErrKeyNotFound for keys that are not in the
+	// OptionSettings line, instead of appending them.
+	SkipMissing bool
+
+	path     string
+	encoding Encoding
+	settings *optionSettings
+}
+
+// Load reads and parses the PalWorldSettings.ini file at path.
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, content)
+}
+
+// Parse parses INI content that will be saved to path. The content may be UTF-8
+// or UTF-16LE, with or without BOM, and is saved back in the same encoding.
+func Parse(path string, content []byte) (*Config, error) {
+	text, encoding, err := Decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing %s: %v", ErrInvalidConfig, path, err)
+	}
+	settings, err := parseOptionSettings(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing %s: %v", ErrInvalidConfig, path, err)
+	}
+	return &Config{KeepBackups: DefaultKeepBackups, path: path, encoding: encoding, settings: settings}, nil
+}
+
+// Path returns the file the config is saved to.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Encoding returns the encoding the config was read in and is saved in.
+func (c *Config) Encoding() Encoding {
+	return c.encoding
+}
+
+// Get returns the value of key in the same form Set accepts it,
+// without the quotes or parentheses used in the INI file.
+func (c *Config) Get(key string) (string, bool) {
+	raw, ok := c.settings.Get(key)
+	if !ok {
+		return "", false
+	}
+	spec, _ := LookupKey(key)
+	if spec.Type == TypeCrossplayPlatforms {
+		return strings.Trim(raw, "() "), true
+	}
+	if spec.Quoted {
+		return unquoteString(raw), true
+	}
+	return raw, true
+}
+
+// Has reports whether key is present in the OptionSettings line.
+func (c *Config) Has(key string) bool {
+	return c.settings.lookup(key) != nil
+}
+
+// Set validates value against the rule of key and updates it in the config.
+// An empty value is always accepted and clears the key. A key that is missing
+// from the file is appended, unless SkipMissing is set.
+func (c *Config) Set(key, value string) error {
+	if !IsKnownKey(key) {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	if value != "" {
+		canonical, err := ValidateValue(key, value)
+		if err != nil {
+			return err
+		}
+		value = canonical
+	}
+	raw := formatValue(key, value)
+	if c.settings.Set(key, raw) {
+		return nil
+	}
+	if c.SkipMissing {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	c.settings.Append(key, raw)
+	return nil
+}
+
+// Validate checks every known key present in the config against its rule,
+// and fails on cross-field constraints with error severity.
+func (c *Config) Validate() error {
+	var errs []error
+	for _, entry := range c.settings.entries {
+		key := entry.Key()
+		if !IsKnownKey(key) {
+			continue
+		}
+		value, _ := c.Get(key)
+		if value == "" {
+			continue
+		}
+		if _, err := ValidateValue(key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, finding := range c.CheckConstraints() {
+		if finding.Severity == SeverityError {
+			errs = append(errs, finding)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Bytes returns the serialized INI content in the encoding of the file.
+func (c *Config) Bytes() []byte {
+	return c.encoding.Encode(c.settings.Bytes())
+}
+
+// Text returns the serialized INI content as UTF-8 without BOM.
+func (c *Config) Text() []byte {
+	return c.settings.Bytes()
+}
+
+// RedactedBytes returns the serialized INI content as UTF-8 without BOM, with the values of secret keys masked.
+func (c *Config) RedactedBytes() []byte {
+	return c.settings.render(func(entry *optionEntry) string {
+		// Decide per entry, a duplicated key may hold another secret than the first one
+		key := entry.Key()
+		if spec, ok := LookupKey(key); ok && spec.Secret && entry.hasValue && unquoteString(entry.value) != "" {
+			return formatValue(key, RedactedValue)
+		}
+		return entry.value
+	})
+}
+
+// Save writes the config back to its path. It does nothing when the file already
+// has this content. Otherwise the current file is backed up first and replaced atomically.
+func (c *Config) Save() error {
+	_, err := c.SaveWithBackup()
+	return err
+}
+
+// SaveWithBackup is like Save and also returns the path of the backup it made,
+// or an empty string when it made none.
+func (c *Config) SaveWithBackup() (string, error) {
+	content := c.Bytes()
+	if current, err := os.ReadFile(c.path); err == nil && bytes.Equal(current, content) {
+		return "", nil
+	}
+	backupPath, err := BackupFile(c.path, c.KeepBackups)
+	if err != nil {
+		return "", fmt.Errorf("backing up %s: %w", c.path, err)
+	}
+	return backupPath, WriteFileAtomic(c.path, content, 0644)
+}
+
+// ValidateValue checks value against the validation rule of key and
+// returns it in the canonical form that is written to the INI file.
+func ValidateValue(key, value string) (string, error) {
+	canonical, _, err := validateValue(key, value, false)
+	return canonical, err
+}
+
+// ClampValue is like ValidateValue, but a number outside the allowed range of key
+// is clamped to the nearest bound instead of rejected. It reports whether that happened.
+func ClampValue(key, value string) (string, bool, error) {
+	return validateValue(key, value, true)
+}
+
+func validateValue(key, value string, clamp bool) (string, bool, error) {
+	spec, ok := LookupKey(key)
+	if !ok {
+		return "", false, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	rule, ok := ValidationRules[spec.Type]
+	if !ok {
+		return "", false, fmt.Errorf("no validation rule found for key: %s", key)
+	}
+	canonical, err := rule(spec, value)
+	if err != nil {
+		return "", false, &ValidationError{Key: key, Value: value, Rule: spec.Type, Reason: err.Error()}
+	}
+
+	if spec.Range == nil {
+		return canonical, false, nil
+	}
+	num, err := strconv.ParseFloat(canonical, 64)
+	if err != nil || spec.Range.Contains(num) {
+		return canonical, false, nil
+	}
+	if !clamp {
+		return "", false, &ValidationError{
+			Key:    key,
+			Value:  value,
+			Rule:   spec.Type,
+			Reason: fmt.Sprintf("must be between %s", spec.Range),
+			Range:  spec.Range,
+		}
+	}
+	return spec.formatNumber(spec.Range.Clamp(num)), true, nil
+}
+
+// formatValue converts value into the raw form written to the INI file.
+func formatValue(key, value string) string {
+	spec, _ := LookupKey(key)
+	// Special handling for CrossplayPlatforms - add parentheses
+	if spec.Type == TypeCrossplayPlatforms && value != "" {
+		// Remove any existing parentheses and trim
+		value = strings.Trim(value, "() ")
+		if value != "" {
+			value = fmt.Sprintf(`(%s)`, value)
+		}
+	}
+	// If the key requires quotes, add escaped quotes around the value
+	if spec.Quoted {
+		value = quoteString(value)
+	}
+	return value
+}
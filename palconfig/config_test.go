@@ -0,0 +1,58 @@
+package palconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateValueRange(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string
+		clamped string
+	}{
+		{"ExpRate", "2", "2.000000", "2.000000"},
+		{"ExpRate", "100", "100.000000", "100.000000"},
+		{"ExpRate", "100000", "", "100.000000"},
+		{"PalCaptureRate", "0", "0.000000", "0.000000"},
+		{"WorkSpeedRate", "1e9", "", "100.000000"},
+		{"ServerPlayerMaxNum", "32", "32", "32"},
+		{"ServerPlayerMaxNum", "33", "", "32"},
+		{"CoopPlayerMaxNum", "0", "", "1"},
+		{"GuildPlayerMaxNum", "1000", "", "100"},
+		{"BaseCampWorkerMaxNum", "51", "", "50"},
+		{"DropItemMaxNum", "99999", "", "5000"},
+		{"PublicPort", "99999", "", "65535"},
+		{"PublicPort", "+80", "80", "80"},
+		{"RCONPort", "0080", "80", "80"},
+		{"SupplyDropSpan", "100000", "100000", "100000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			got, err := ValidateValue(tt.key, tt.value)
+			if tt.want == "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Range == nil {
+					t.Errorf("ValidateValue = %q, %v, want a range error", got, err)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("ValidateValue = %q, %v, want %q", got, err, tt.want)
+			}
+
+			clamped, ok, err := ClampValue(tt.key, tt.value)
+			if err != nil || clamped != tt.clamped || ok != (tt.want == "") {
+				t.Errorf("ClampValue = %q, %v, %v, want %q", clamped, ok, err, tt.clamped)
+			}
+		})
+	}
+}
+
+func TestValidateValueRangeMessage(t *testing.T) {
+	_, err := ValidateValue("ExpRate", "100000")
+	if err == nil || !strings.HasSuffix(err.Error(), "must be between 0 and 100") {
+		t.Errorf("ValidateValue error = %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package palconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity tells whether a finding blocks writing the config.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem reported by a cross-field constraint.
+type Finding struct {
+	Severity Severity
+	Keys     []string
+	Message  string
+}
+
+func (f Finding) Error() string {
+	return fmt.Sprintf("%s: %s", strings.Join(f.Keys, ", "), f.Message)
+}
+
+// Constraint checks a relation between several keys of a config.
+type Constraint struct {
+	Name  string
+	Check func(c *Config) []Finding
+}
+
+// Constraints holds the cross-field rules evaluated by CheckConstraints
+var Constraints = []Constraint{
+	{
+		Name: "CoopPlayerMaxNum",
+		Check: func(c *Config) []Finding {
+			// A co-op session can not hold more players than the server
+			return c.atMost("CoopPlayerMaxNum", "ServerPlayerMaxNum", SeverityError)
+		},
+	},
+	{
+		Name: "GuildPlayerMaxNum",
+		Check: func(c *Config) []Finding {
+			// Guild members can be offline, so a larger guild is allowed but rarely intended
+			return c.atMost("GuildPlayerMaxNum", "ServerPlayerMaxNum", SeverityWarning)
+		},
+	},
+	{
+		Name: "BaseCampMaxNumInGuild",
+		Check: func(c *Config) []Finding {
+			// The server wide base limit is reached before the guild limit
+			return c.atMost("BaseCampMaxNumInGuild", "BaseCampMaxNum", SeverityWarning)
+		},
+	},
+	{
+		Name: "Ports",
+		Check: func(c *Config) []Finding {
+			// Every enabled listener needs its own port
+			ports := []string{"PublicPort"}
+			if c.boolean("RCONEnabled") {
+				ports = append(ports, "RCONPort")
+			}
+			if c.boolean("RESTAPIEnabled") {
+				ports = append(ports, "RESTAPIPort")
+			}
+
+			var findings []Finding
+			for i := 0; i < len(ports); i++ {
+				for j := i + 1; j < len(ports); j++ {
+					a, okA := c.number(ports[i])
+					b, okB := c.number(ports[j])
+					if okA && okB && a == b {
+						findings = append(findings, Finding{
+							Severity: SeverityError,
+							Keys:     []string{ports[i], ports[j]},
+							Message:  fmt.Sprintf("%s and %s both use port %s", ports[i], ports[j], formatBound(a)),
+						})
+					}
+				}
+			}
+			return findings
+		},
+	},
+	{
+		Name: "bIsPvP",
+		Check: func(c *Config) []Finding {
+			if c.boolean("bIsPvP") && !c.boolean("bEnablePlayerToPlayerDamage") {
+				return []Finding{{
+					Severity: SeverityWarning,
+					Keys:     []string{"bIsPvP", "bEnablePlayerToPlayerDamage"},
+					Message:  "PvP is enabled but players can not damage each other",
+				}}
+			}
+			return nil
+		},
+	},
+	{
+		Name: "bCharacterRecreateInHardcore",
+		Check: func(c *Config) []Finding {
+			if c.boolean("bCharacterRecreateInHardcore") && !c.boolean("bHardcore") {
+				return []Finding{{
+					Severity: SeverityWarning,
+					Keys:     []string{"bCharacterRecreateInHardcore", "bHardcore"},
+					Message:  "character recreation only applies when hardcore mode is enabled",
+				}}
+			}
+			return nil
+		},
+	},
+	// Add more constraints as needed
+}
+
+// CheckConstraints evaluates every cross-field constraint against the current values.
+// A key the file does not set is checked with its default.
+func (c *Config) CheckConstraints() []Finding {
+	var findings []Finding
+	for _, constraint := range Constraints {
+		findings = append(findings, constraint.Check(c)...)
+	}
+	return findings
+}
+
+// atMost reports a finding when the value of key is larger than the value of limit.
+func (c *Config) atMost(key, limit string, severity Severity) []Finding {
+	value, okValue := c.number(key)
+	limitValue, okLimit := c.number(limit)
+	if !okValue || !okLimit || value <= limitValue {
+		return nil
+	}
+	return []Finding{{
+		Severity: severity,
+		Keys:     []string{key, limit},
+		Message:  fmt.Sprintf("%s (%s) is larger than %s (%s)", key, formatBound(value), limit, formatBound(limitValue)),
+	}}
+}
+
+// value returns the value of key, or its schema default when the file does not set it,
+// since that is the value the server uses.
+func (c *Config) value(key string) (string, bool) {
+	if value, ok := c.Get(key); ok {
+		return value, true
+	}
+	spec, ok := LookupKey(key)
+	return spec.Default, ok
+}
+
+// number returns the numeric value of key, or false when it is unknown or not a number.
+func (c *Config) number(key string) (float64, bool) {
+	value, ok := c.value(key)
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(value, 64)
+	return num, err == nil
+}
+
+// boolean returns whether key is set to True.
+func (c *Config) boolean(key string) bool {
+	value, _ := c.value(key)
+	return strings.EqualFold(value, "True")
+}
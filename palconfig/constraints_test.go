@@ -0,0 +1,77 @@
+package palconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseLine returns a config whose OptionSettings line has the value line.
+func parseLine(t *testing.T, line string) *Config {
+	t.Helper()
+	config, err := Parse("PalWorldSettings.ini", iniWith(line))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return config
+}
+
+func TestCheckConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string // severity and keys of every finding
+	}{
+		{"defaults", "()", nil},
+		{"co-op above server", "(CoopPlayerMaxNum=8,ServerPlayerMaxNum=4,GuildPlayerMaxNum=4)", []string{"error CoopPlayerMaxNum,ServerPlayerMaxNum"}},
+		{"co-op equal to server", "(CoopPlayerMaxNum=4,ServerPlayerMaxNum=4,GuildPlayerMaxNum=4)", nil},
+		{"default co-op above server", "(ServerPlayerMaxNum=2,GuildPlayerMaxNum=2)", []string{"error CoopPlayerMaxNum,ServerPlayerMaxNum"}},
+		{"guild above server", "(GuildPlayerMaxNum=50,ServerPlayerMaxNum=32)", []string{"warning GuildPlayerMaxNum,ServerPlayerMaxNum"}},
+		{"guild above default server", "(GuildPlayerMaxNum=50)", []string{"warning GuildPlayerMaxNum,ServerPlayerMaxNum"}},
+		{"bases in guild above bases", "(BaseCampMaxNumInGuild=10,BaseCampMaxNum=5)", []string{"warning BaseCampMaxNumInGuild,BaseCampMaxNum"}},
+		{"RCON port on the public port", "(RCONEnabled=True,RCONPort=8211,PublicPort=8211)", []string{"error PublicPort,RCONPort"}},
+		{"RCON port on the default public port", "(RCONEnabled=True,RCONPort=8211)", []string{"error PublicPort,RCONPort"}},
+		{"RCON disabled", "(RCONEnabled=False,RCONPort=8211)", nil},
+		{"REST API on the default RCON port", "(RCONEnabled=True,RESTAPIEnabled=True,RESTAPIPort=25575)", []string{"error RCONPort,RESTAPIPort"}},
+		{"all ports equal", "(PublicPort=1,RCONEnabled=True,RCONPort=1,RESTAPIEnabled=True,RESTAPIPort=1)",
+			[]string{"error PublicPort,RCONPort", "error PublicPort,RESTAPIPort", "error RCONPort,RESTAPIPort"}},
+		{"PvP without damage", "(bIsPvP=True)", []string{"warning bIsPvP,bEnablePlayerToPlayerDamage"}},
+		{"PvP with damage", "(bIsPvP=True,bEnablePlayerToPlayerDamage=True)", nil},
+		{"recreate without hardcore", "(bCharacterRecreateInHardcore=True)", []string{"warning bCharacterRecreateInHardcore,bHardcore"}},
+		{"recreate with hardcore", "(bCharacterRecreateInHardcore=True,bHardcore=True)", nil},
+		{"not a number", "(CoopPlayerMaxNum=many,ServerPlayerMaxNum=2,GuildPlayerMaxNum=2)", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, finding := range parseLine(t, tt.line).CheckConstraints() {
+				got = append(got, string(finding.Severity)+" "+strings.Join(finding.Keys, ","))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConstraintsDefaultTemplate(t *testing.T) {
+	config, err := Parse("DefaultPalWorldSettings.ini", DefaultTemplate())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if findings := config.CheckConstraints(); len(findings) != 0 {
+		t.Errorf("the default template has findings: %v", findings)
+	}
+}
+
+func TestConstraintMessage(t *testing.T) {
+	findings := parseLine(t, "(GuildPlayerMaxNum=50)").CheckConstraints()
+	if len(findings) != 1 {
+		t.Fatalf("findings = %v", findings)
+	}
+	want := "GuildPlayerMaxNum (50) is larger than ServerPlayerMaxNum (32)"
+	if findings[0].Message != want {
+		t.Errorf("Message = %q, want %q", findings[0].Message, want)
+	}
+}
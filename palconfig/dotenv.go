@@ -0,0 +1,248 @@
+package palconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Sources of a value, as reported in KeyResult.Source. A .env file is reported by its path.
+const (
+	SourceDefaults    = "defaults"    // the default template that was copied
+	SourceINI         = "ini"         // the existing PalWorldSettings.ini
+	SourceEnvironment = "environment" // the environment of the process
+)
+
+// DotenvVar is a single assignment in a .env file.
+type DotenvVar struct {
+	Name  string
+	Value string
+	Line  int
+}
+
+// ParseDotenv parses the content of a .env file. It accepts blank lines, "#" comments,
+// an optional "export " prefix and values that are unquoted, between single quotes
+// (taken literally) or between double quotes (with \n, \r, \t, \\, \", \$ and \` escapes).
+// Quoted values may span several lines. Later assignments override earlier ones.
+func ParseDotenv(content []byte) ([]DotenvVar, error) {
+	content = bytes.TrimPrefix(content, bomUTF8)
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+
+	var vars []DotenvVar
+	line := 1
+	for len(text) > 0 {
+		var current string
+		current, text, _ = strings.Cut(text, "\n")
+		start := line
+		line++
+
+		trimmed := strings.TrimSpace(current)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(trimmed, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			trimmed = strings.TrimSpace(rest)
+		}
+
+		name, value, ok := strings.Cut(trimmed, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isEnvName(name) {
+			return nil, fmt.Errorf("line %d: expected NAME=value", start)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			// A quoted value ends at the matching quote, which may be on a later line
+			quote := value[0]
+			body, after, closed := scanQuoted(value[1:], quote)
+			for !closed && len(text) > 0 {
+				var next string
+				next, text, _ = strings.Cut(text, "\n")
+				line++
+				var more string
+				more, after, closed = scanQuoted(next, quote)
+				body += "\n" + more
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated quoted value of %s", start, name)
+			}
+			after = strings.TrimSpace(after)
+			if after != "" && after[0] != '#' {
+				return nil, fmt.Errorf("line %d: unexpected text after the quoted value of %s", start, name)
+			}
+			if quote == '"' {
+				body = unescapeDotenv(body)
+			}
+			value = body
+		} else {
+			// An unquoted value ends at a comment
+			for i := 0; i < len(value); i++ {
+				if value[i] == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+					value = value[:i]
+					break
+				}
+			}
+			value = strings.TrimSpace(value)
+		}
+
+		vars = append(vars, DotenvVar{Name: name, Value: value, Line: start})
+	}
+	return vars, nil
+}
+
+// scanQuoted returns the text of s up to the closing quote and the text after it,
+// and reports whether the quote was found. Inside double quotes a backslash escapes
+// the next character; the escapes are kept for unescapeDotenv.
+func scanQuoted(s string, quote byte) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"' && i+1 < len(s):
+			i++
+		case s[i] == quote:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// unescapeDotenv resolves the escapes of a double quoted .env value, the reverse of QuoteDotenv.
+func unescapeDotenv(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '\\', '"', '$', '`':
+			b.WriteByte(s[i])
+		default:
+			// Unknown escapes are kept as they are
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// isEnvName reports whether name is a valid environment variable name.
+func isEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// envLayer is one source of environment variables
+type envLayer struct {
+	source string
+	lookup func(name string) (string, bool)
+}
+
+// Environment is an ordered stack of variable sources. A variable set in a later
+// layer overrides the same variable in every earlier layer.
+type Environment struct {
+	layers []envLayer
+}
+
+// AddDotenvFile adds the variables of the .env file at path as the next layer.
+func (e *Environment) AddDotenvFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseDotenv(content)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	vars := make(map[string]string, len(parsed))
+	for _, v := range parsed {
+		vars[v.Name] = v.Value
+	}
+	e.layers = append(e.layers, envLayer{source: path, lookup: func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}})
+	return nil
+}
+
+// AddProcess adds the environment of the process as the next layer.
+func (e *Environment) AddProcess() {
+	e.layers = append(e.layers, envLayer{source: SourceEnvironment, lookup: os.LookupEnv})
+}
+
+// Lookup returns the value of the variable name from the last layer that sets it, and that layer.
+func (e *Environment) Lookup(name string) (value, source string, ok bool) {
+	for i := len(e.layers) - 1; i >= 0; i-- {
+		if value, ok := e.layers[i].lookup(name); ok {
+			return value, e.layers[i].source, true
+		}
+	}
+	return "", "", false
+}
+
+// ErrConflictingEnv is returned by LookupKey when both a variable and its _FILE variant are set
+var ErrConflictingEnv = errors.New("conflicting variables")
+
+// FileSuffix marks a variable that holds the path of a file with the value, as used for Docker and Kubernetes secrets
+const FileSuffix = "_FILE"
+
+// EnvValue is the value of a key found in an Environment.
+type EnvValue struct {
+	Name   string // variable that was used, ending in FileSuffix when the value was read from a file
+	Value  string
+	Source string // layer that set the variable
+	File   string // file the value was read from, empty when it was set directly
+}
+
+// LookupKey is like Lookup for the variable of key. Like Key.EnvName, it uses
+// key.Env when that is set and not empty, and key.EnvFallback otherwise.
+// Every variable X can also be given as X_FILE, the path of a file holding the value
+// without its trailing newline. Setting both X and X_FILE is an error.
+func (e *Environment) LookupKey(key Key) (EnvValue, bool, error) {
+	v, ok, err := e.lookupValue(key.Env)
+	if err != nil || key.EnvFallback == "" || (ok && v.Value != "") {
+		return v, ok, err
+	}
+	return e.lookupValue(key.EnvFallback)
+}
+
+// lookupValue looks up name or name+FileSuffix and reads the file of the latter.
+func (e *Environment) lookupValue(name string) (EnvValue, bool, error) {
+	value, source, ok := e.Lookup(name)
+	path, fileSource, fileOK := e.Lookup(name + FileSuffix)
+	switch {
+	case ok && fileOK:
+		return EnvValue{}, false, fmt.Errorf("%w: both %s (%s) and %s%s (%s) are set", ErrConflictingEnv, name, source, name, FileSuffix, fileSource)
+	case ok:
+		return EnvValue{Name: name, Value: value, Source: source}, true, nil
+	case !fileOK:
+		return EnvValue{Name: name}, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return EnvValue{}, false, fmt.Errorf("reading %s%s: %w", name, FileSuffix, err)
+	}
+	// Secret files usually end with a newline that is not part of the value
+	value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	return EnvValue{Name: name + FileSuffix, Value: value, Source: fileSource, File: path}, true, nil
+}
@@ -0,0 +1,85 @@
+package palconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []DotenvVar
+	}{
+		{"plain", "A=1\nB=two words\n", []DotenvVar{{"A", "1", 1}, {"B", "two words", 2}}},
+		{"blank lines and comments", "# comment\n\n  # indented\nA=1\n", []DotenvVar{{"A", "1", 4}}},
+		{"export prefix", "export A=1\nexport\tB=2\nexported=3\n", []DotenvVar{{"A", "1", 1}, {"B", "2", 2}, {"exported", "3", 3}}},
+		{"spaces around the separator", "A = 1 \n", []DotenvVar{{"A", "1", 1}}},
+		{"empty value", "A=\nB= # comment\n", []DotenvVar{{"A", "", 1}, {"B", "", 2}}},
+		{"inline comment", "A=1 # one\nB=2\t# two\n", []DotenvVar{{"A", "1", 1}, {"B", "2", 2}}},
+		{"hash inside a value", "A=a#b\nB=#b\n", []DotenvVar{{"A", "a#b", 1}, {"B", "", 2}}},
+		{"comment after quotes", "A=\"a # b\" # comment\nB='a # b'#comment\n", []DotenvVar{{"A", "a # b", 1}, {"B", "a # b", 2}}},
+		{"single quotes are literal", `A='a\nb "c" $D'` + "\n", []DotenvVar{{"A", `a\nb "c" $D`, 1}}},
+		{"double quote escapes", `A="a\nb\tc\\d\"e\$f\` + "`" + `g\rh"` + "\n", []DotenvVar{{"A", "a\nb\tc\\d\"e$f`g\rh", 1}}},
+		{"unknown escape is kept", `A="C:\Pal\x"` + "\n", []DotenvVar{{"A", `C:\Pal\x`, 1}}},
+		{"double quoted multi-line", "A=\"one\ntwo\nthree\"\nB=1\n", []DotenvVar{{"A", "one\ntwo\nthree", 1}, {"B", "1", 4}}},
+		{"single quoted multi-line", "A='one\n\"two\"'\nB=1\n", []DotenvVar{{"A", "one\n\"two\"", 1}, {"B", "1", 3}}},
+		{"escaped quote before a line break", "A=\"one \\\"\ntwo\"\n", []DotenvVar{{"A", "one \"\ntwo", 1}}},
+		{"CRLF", "A=1\r\nB=\"x\r\ny\"\r\n", []DotenvVar{{"A", "1", 1}, {"B", "x\ny", 2}}},
+		{"BOM", "\xEF\xBB\xBFA=1\n", []DotenvVar{{"A", "1", 1}}},
+		{"no final newline", "A=1", []DotenvVar{{"A", "1", 1}}},
+		{"value with equals", "A=b=c\n", []DotenvVar{{"A", "b=c", 1}}},
+		{"repeated name", "A=1\nA=2\n", []DotenvVar{{"A", "1", 1}, {"A", "2", 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotenv([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("ParseDotenv: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDotenv = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing separator", "A=1\nB\n", "line 2: expected NAME=value"},
+		{"invalid name", "1A=1\n", "line 1: expected NAME=value"},
+		{"empty name", "=1\n", "line 1: expected NAME=value"},
+		{"unterminated double quotes", "A=1\nB=\"one\ntwo\n", "line 2: unterminated quoted value of B"},
+		{"unterminated single quotes", "A='one\n", "line 1: unterminated quoted value of A"},
+		{"text after quotes", "A=\"one\" two\n", "line 1: unexpected text after the quoted value of A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDotenv([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseDotenv error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteDotenvRoundTrip(t *testing.T) {
+	values := []string{"", "plain", "two words", `say "hi"`, `C:\Pal\`, "one\ntwo", "a\tb", "$HOME", "a # b"}
+
+	for _, value := range values {
+		vars, err := ParseDotenv([]byte("A=" + QuoteDotenv(value) + "\n"))
+		if err != nil {
+			t.Fatalf("ParseDotenv(%s): %v", QuoteDotenv(value), err)
+		}
+		if len(vars) != 1 || vars[0].Value != value {
+			t.Errorf("round trip of %q = %+v", value, vars)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package palconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrUnsupportedEncoding is returned for files that are neither UTF-8 nor UTF-16LE
+var ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Encoding describes how a settings file is stored on disk, so it can be written
+// back the same way. The zero value is UTF-8 without BOM and with LF line endings.
+type Encoding struct {
+	UTF16LE bool // UTF-16 little endian, as Unreal writes it on some platforms
+	BOM     bool // the file starts with a byte order mark
+	CRLF    bool // every line ends with CRLF
+}
+
+func (e Encoding) String() string {
+	parts := []string{"UTF-8"}
+	if e.UTF16LE {
+		parts[0] = "UTF-16LE"
+	}
+	if e.BOM {
+		parts = append(parts, "BOM")
+	}
+	if e.CRLF {
+		parts = append(parts, "CRLF")
+	} else {
+		parts = append(parts, "LF")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Decode detects the encoding of content and returns it as UTF-8 without BOM.
+// Line endings are left as they are.
+func Decode(content []byte) ([]byte, Encoding, error) {
+	var enc Encoding
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		enc.BOM = true
+		content = content[len(bomUTF8):]
+	case bytes.HasPrefix(content, bomUTF16LE):
+		enc.UTF16LE, enc.BOM = true, true
+		content = content[len(bomUTF16LE):]
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return nil, enc, fmt.Errorf("%w: UTF-16BE", ErrUnsupportedEncoding)
+	case len(content) >= 4 && content[0] != 0 && content[1] == 0 && content[2] != 0 && content[3] == 0:
+		// UTF-16LE without BOM, the INI starts with ASCII text
+		enc.UTF16LE = true
+	}
+
+	if enc.UTF16LE {
+		if len(content)%2 != 0 {
+			return nil, enc, fmt.Errorf("%w: UTF-16LE content has an odd number of bytes", ErrUnsupportedEncoding)
+		}
+		units := make([]uint16, len(content)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(content[2*i:])
+		}
+		content = []byte(string(utf16.Decode(units)))
+	}
+
+	lines := bytes.Count(content, []byte("\n"))
+	enc.CRLF = lines > 0 && bytes.Count(content, []byte("\r\n")) == lines
+	return content, enc, nil
+}
+
+// Encode converts UTF-8 content to the encoding e, adding the BOM and turning
+// lone LF line endings into CRLF when e asks for them.
+func (e Encoding) Encode(content []byte) []byte {
+	if e.CRLF {
+		lines := bytes.Count(content, []byte("\n"))
+		if bytes.Count(content, []byte("\r\n")) != lines {
+			content = bytes.ReplaceAll(bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+		}
+	}
+
+	if !e.UTF16LE {
+		if e.BOM {
+			return append(append([]byte{}, bomUTF8...), content...)
+		}
+		return content
+	}
+
+	units := utf16.Encode([]rune(string(content)))
+	out := make([]byte, 0, len(bomUTF16LE)+2*len(units))
+	if e.BOM {
+		out = append(out, bomUTF16LE...)
+	}
+	for _, unit := range units {
+		out = binary.LittleEndian.AppendUint16(out, unit)
+	}
+	return out
+}
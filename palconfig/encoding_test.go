@@ -0,0 +1,102 @@
+package palconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16le encodes s as UTF-16 little endian without BOM.
+func utf16le(s string) []byte {
+	var out []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, unit)
+	}
+	return out
+}
+
+func TestDecodeEncode(t *testing.T) {
+	const text = SectionName + "\nOptionSettings=(ServerName=\"Pâl ワールド\")\n"
+	const crlf = SectionName + "\r\nOptionSettings=(ServerName=\"Pâl ワールド\")\r\n"
+	const mixed = SectionName + "\r\nOptionSettings=(ServerName=\"Pâl ワールド\")\n"
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+		enc     Encoding
+	}{
+		{"UTF-8", []byte(text), text, Encoding{}},
+		{"UTF-8 BOM", append(append([]byte{}, bomUTF8...), text...), text, Encoding{BOM: true}},
+		{"UTF-8 CRLF", []byte(crlf), crlf, Encoding{CRLF: true}},
+		{"UTF-8 mixed line endings", []byte(mixed), mixed, Encoding{}},
+		{"UTF-8 no line ending", []byte(SectionName), SectionName, Encoding{}},
+		{"UTF-16LE BOM", append(append([]byte{}, bomUTF16LE...), utf16le(text)...), text, Encoding{UTF16LE: true, BOM: true}},
+		{"UTF-16LE no BOM", utf16le(text), text, Encoding{UTF16LE: true}},
+		{"UTF-16LE BOM CRLF", append(append([]byte{}, bomUTF16LE...), utf16le(crlf)...), crlf, Encoding{UTF16LE: true, BOM: true, CRLF: true}},
+		{"UTF-16LE mixed line endings", utf16le(mixed), mixed, Encoding{UTF16LE: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, enc, err := Decode(tt.content)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if string(decoded) != tt.want {
+				t.Errorf("Decode content = %q, want %q", decoded, tt.want)
+			}
+			if enc != tt.enc {
+				t.Errorf("Decode encoding = %s, want %s", enc, tt.enc)
+			}
+			if encoded := enc.Encode(decoded); !bytes.Equal(encoded, tt.content) {
+				t.Errorf("Encode(Decode(x)) = %q, want %q", encoded, tt.content)
+			}
+		})
+	}
+}
+
+func TestEncodeCRLF(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"LF", "a\nb\n", "a\r\nb\r\n"},
+		{"CRLF", "a\r\nb\r\n", "a\r\nb\r\n"},
+		{"new line added to a CRLF file", "a\r\nb\r\nc\n", "a\r\nb\r\nc\r\n"},
+		{"no line ending", "a", "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Encoding{CRLF: true}).Encode([]byte(tt.content)); string(got) != tt.want {
+				t.Errorf("Encode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := (Encoding{}).Encode([]byte("a\nb\r\n")); string(got) != "a\nb\r\n" {
+		t.Errorf("Encode without CRLF changed line endings: %q", got)
+	}
+}
+
+func TestDecodeUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"UTF-16BE", append(append([]byte{}, bomUTF16BE...), 0, '[', 0, 'a')},
+		{"UTF-16LE odd length", append(utf16le(SectionName), 'x')},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.content); !errors.Is(err, ErrUnsupportedEncoding) {
+				t.Errorf("Decode error = %v, want %v", err, ErrUnsupportedEncoding)
+			}
+		})
+	}
+}
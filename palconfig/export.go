@@ -0,0 +1,169 @@
+package palconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExportFormat names an output format of Export.
+type ExportFormat string
+
+// Formats supported by Export
+const (
+	ExportDotenv     ExportFormat = "env"        // a .env file
+	ExportCompose    ExportFormat = "compose"    // a docker-compose environment: block
+	ExportKubernetes ExportFormat = "kubernetes" // a ConfigMap, and a Secret for the secret keys
+)
+
+// ExportFormats lists every format accepted by Export
+var ExportFormats = []ExportFormat{ExportDotenv, ExportCompose, ExportKubernetes}
+
+// DefaultExportName is the name of the Kubernetes objects when ExportOptions.Name is empty
+const DefaultExportName = "palworld-settings"
+
+// ExportOptions controls Export.
+type ExportOptions struct {
+	Format ExportFormat
+	All    bool   // include values equal to the default
+	Name   string // name of the Kubernetes objects
+}
+
+// ExportVar is an environment variable that reproduces a value of the config.
+type ExportVar struct {
+	Key    string
+	Env    string
+	Value  string
+	Secret bool
+}
+
+// ExportVars returns the environment variables that reproduce the config, in schema order.
+// Keys that are missing from the file are left out, as are keys with their default value
+// unless all is set.
+func (c *Config) ExportVars(all bool) []ExportVar {
+	var vars []ExportVar
+	for _, spec := range Schema {
+		value, ok := c.Get(spec.Name)
+		if !ok || (!all && !ChangedFromDefault(spec.Name, value)) {
+			continue
+		}
+		vars = append(vars, ExportVar{Key: spec.Name, Env: spec.Env, Value: value, Secret: spec.Secret})
+	}
+	return vars
+}
+
+// Export renders the environment variables that reproduce the config in options.Format.
+// Secret values are included unmasked.
+func (c *Config) Export(options ExportOptions) ([]byte, error) {
+	vars := c.ExportVars(options.All)
+
+	var b strings.Builder
+	switch options.Format {
+	case ExportDotenv:
+		for _, v := range vars {
+			fmt.Fprintf(&b, "%s=%s\n", v.Env, QuoteDotenv(v.Value))
+		}
+	case ExportCompose:
+		b.WriteString("environment:\n")
+		for _, v := range vars {
+			// Compose interpolates "$", "$$" is a literal dollar sign
+			fmt.Fprintf(&b, "  %s: %s\n", v.Env, quoteYAML(strings.ReplaceAll(v.Value, "$", "$$")))
+		}
+	case ExportKubernetes:
+		name := options.Name
+		if name == "" {
+			name = DefaultExportName
+		}
+		var data, secrets []ExportVar
+		for _, v := range vars {
+			if v.Secret {
+				secrets = append(secrets, v)
+			} else {
+				data = append(data, v)
+			}
+		}
+
+		fmt.Fprintf(&b, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n", name)
+		writeYAMLMap(&b, "data", data)
+		if len(secrets) > 0 {
+			fmt.Fprintf(&b, "---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: %s\ntype: Opaque\n", name)
+			writeYAMLMap(&b, "stringData", secrets)
+		}
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", options.Format)
+	}
+	return []byte(b.String()), nil
+}
+
+// writeYAMLMap writes vars as the YAML mapping field.
+func writeYAMLMap(b *strings.Builder, field string, vars []ExportVar) {
+	if len(vars) == 0 {
+		fmt.Fprintf(b, "%s: {}\n", field)
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", field)
+	for _, v := range vars {
+		fmt.Fprintf(b, "  %s: %s\n", v.Env, quoteYAML(v.Value))
+	}
+}
+
+// QuoteDotenv returns value in the form it is written to a .env file. Plain values are
+// written as they are, others between single quotes, or between double quotes with
+// escapes when they contain a single quote or a line break.
+func QuoteDotenv(value string) string {
+	plain := value != ""
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+", r)) {
+			plain = false
+			break
+		}
+	}
+	if plain || value == "" {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\', '"', '$', '`':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// quoteYAML returns value as a double quoted YAML scalar.
+func quoteYAML(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
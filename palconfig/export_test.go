@@ -0,0 +1,135 @@
+package palconfig
+
+import "testing"
+
+func TestQuoteDotenv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"1.000000", "1.000000"},
+		{"Steam,Xbox", "Steam,Xbox"},
+		{"https://api.palworldgame.com/api/banlist.txt", "https://api.palworldgame.com/api/banlist.txt"},
+		{"two words", "'two words'"},
+		{`say "hi"`, `'say "hi"'`},
+		{`C:\Pal`, `'C:\Pal'`},
+		{"$HOME", "'$HOME'"},
+		{"a # b", "'a # b'"},
+		{"a\tb", "'a\tb'"},
+		{"it's", `"it's"`},
+		{`it's "$5" \o/`, `"it's \"\$5\" \\o/"`},
+		{"one\ntwo", `"one\ntwo"`},
+		{"one\r\ntwo", `"one\r\ntwo"`},
+		{"`cmd`\n", "\"\\`cmd\\`\\n\""},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteDotenv(tt.value); got != tt.want {
+			t.Errorf("QuoteDotenv(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteYAML(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", `""`},
+		{"plain", `"plain"`},
+		{"True", `"True"`},
+		{"32", `"32"`},
+		{"a: b # c", `"a: b # c"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\Pal`, `"C:\\Pal"`},
+		{"one\ntwo\r\n", `"one\ntwo\r\n"`},
+		{"a\tb", `"a\tb"`},
+		{"bell\a del\x7f", `"bell\x07 del\x7F"`},
+		{"Pâl ワールド", `"Pâl ワールド"`},
+	}
+
+	for _, tt := range tests {
+		if got := quoteYAML(tt.value); got != tt.want {
+			t.Errorf("quoteYAML(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	config := parseLine(t, `(Difficulty=None,ExpRate=2.000000,ServerName="it's $5",AdminPassword="p\"w",ServerPlayerMaxNum=32)`)
+
+	tests := []struct {
+		name    string
+		options ExportOptions
+		want    string
+	}{
+		{
+			"env",
+			ExportOptions{Format: ExportDotenv},
+			"EXP_RATE=2.000000\n" +
+				"SERVER_NAME=\"it's \\$5\"\n" +
+				"ADMIN_PASSWORD='p\"w'\n",
+		},
+		{
+			"env with defaults",
+			ExportOptions{Format: ExportDotenv, All: true},
+			"DIFFICULTY=None\n" +
+				"EXP_RATE=2.000000\n" +
+				"MAX_PLAYERS=32\n" +
+				"SERVER_NAME=\"it's \\$5\"\n" +
+				"ADMIN_PASSWORD='p\"w'\n",
+		},
+		{
+			"compose",
+			ExportOptions{Format: ExportCompose},
+			"environment:\n" +
+				"  EXP_RATE: \"2.000000\"\n" +
+				"  SERVER_NAME: \"it's $$5\"\n" +
+				"  ADMIN_PASSWORD: \"p\\\"w\"\n",
+		},
+		{
+			"kubernetes",
+			ExportOptions{Format: ExportKubernetes},
+			"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: palworld-settings\n" +
+				"data:\n" +
+				"  EXP_RATE: \"2.000000\"\n" +
+				"  SERVER_NAME: \"it's $5\"\n" +
+				"---\n" +
+				"apiVersion: v1\nkind: Secret\nmetadata:\n  name: palworld-settings\ntype: Opaque\n" +
+				"stringData:\n" +
+				"  ADMIN_PASSWORD: \"p\\\"w\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := config.Export(tt.options)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Export =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportKubernetesWithoutSecrets(t *testing.T) {
+	config := parseLine(t, `(Difficulty=None,AdminPassword="")`)
+	got, err := config.Export(ExportOptions{Format: ExportKubernetes, Name: "pal"})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: pal\ndata: {}\n"
+	if string(got) != want {
+		t.Errorf("Export =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if _, err := parseLine(t, "()").Export(ExportOptions{Format: "toml"}); err == nil {
+		t.Error("Export accepted an unknown format")
+	}
+}
@@ -0,0 +1,290 @@
+package palconfig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// optionSettingsKey is the INI key holding the Unreal struct with every server setting
+const optionSettingsKey = "OptionSettings"
+
+// optionEntry is a single Key=Value pair inside the OptionSettings struct.
+// The raw text is kept so an untouched entry is written back exactly as it was read.
+type optionEntry struct {
+	name     string // key as written in the file, including surrounding whitespace
+	hasValue bool   // false for a bare token without "="
+	value    string // raw value text, including quotes or parentheses
+}
+
+// Key returns the key name without surrounding whitespace.
+func (e *optionEntry) Key() string {
+	return strings.TrimSpace(e.name)
+}
+
+// optionSettings is an ordered model of the OptionSettings=(...) line.
+// Serializing it without changes reproduces the original content byte for byte.
+type optionSettings struct {
+	head    []byte // everything up to and including the opening "("
+	entries []*optionEntry
+	tail    string // whitespace or a dangling separator before the closing ")"
+	foot    []byte // the closing ")" and everything after it
+}
+
+// parseOptionSettings locates the OptionSettings line in content and tokenizes its struct body.
+func parseOptionSettings(content []byte) (*optionSettings, error) {
+	start, err := findOptionSettings(content)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, tail, end, err := tokenizeStruct(content, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only whitespace may follow the closing parenthesis on the same line
+	rest := content[end+1:]
+	if lineEnd := bytes.IndexByte(rest, '\n'); lineEnd != -1 {
+		rest = rest[:lineEnd]
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, fmt.Errorf("%w: unexpected text after the closing parenthesis on line %d", ErrUnbalanced, lineOf(content, end))
+	}
+
+	return &optionSettings{
+		head:    content[:start],
+		entries: entries,
+		tail:    tail,
+		foot:    content[end:],
+	}, nil
+}
+
+// findOptionSettings returns the offset just past the "(" that opens the OptionSettings struct
+// in the settings section.
+func findOptionSettings(content []byte) (int, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return 0, ErrEmptyConfig
+	}
+
+	layout := scanLayout(content)
+	if len(layout.sectionLines) == 0 {
+		return 0, ErrMissingSection
+	}
+	if len(layout.options) == 0 {
+		return 0, ErrMissingOptionSettings
+	}
+
+	option := layout.options[0]
+	if option.start == -1 {
+		return 0, fmt.Errorf("%w: the value on line %d is not a struct", ErrMissingOptionSettings, option.line)
+	}
+	return option.start, nil
+}
+
+// tokenizeStruct splits the struct body that starts at start into entries.
+// It understands quoted strings with backslash escapes and nested parentheses,
+// so separators inside a value never end the entry. It returns the offset of the closing ")".
+func tokenizeStruct(content []byte, start int) ([]*optionEntry, string, int, error) {
+	var entries []*optionEntry
+
+	segStart := start
+	eqPos := -1
+	depth := 0
+	inQuotes := false
+
+	for i := start; i < len(content); i++ {
+		c := content[i]
+
+		if inQuotes {
+			switch c {
+			case '\\':
+				// Skip the escaped character, whatever it is
+				i++
+			case '"':
+				inQuotes = false
+			case '\n':
+				return nil, "", 0, fmt.Errorf("%w: unterminated quoted string on line %d", ErrUnbalanced, lineOf(content, i))
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inQuotes = true
+		case '=':
+			if depth == 0 && eqPos == -1 {
+				eqPos = i
+			}
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			// Closing parenthesis of the OptionSettings struct itself
+			segment := string(content[segStart:i])
+			if eqPos == -1 && strings.TrimSpace(segment) == "" {
+				if len(entries) > 0 {
+					// Keep the trailing separator of "(A=1,)"
+					segment = "," + segment
+				}
+				return entries, segment, i, nil
+			}
+			entries = append(entries, newOptionEntry(content, segStart, eqPos, i))
+			return entries, "", i, nil
+		case ',':
+			if depth > 0 {
+				continue
+			}
+			entries = append(entries, newOptionEntry(content, segStart, eqPos, i))
+			segStart = i + 1
+			eqPos = -1
+		case '\n':
+			return nil, "", 0, fmt.Errorf("%w: missing closing parenthesis on line %d", ErrUnbalanced, lineOf(content, i))
+		}
+	}
+
+	// The content ended inside the struct
+	if inQuotes {
+		return nil, "", 0, fmt.Errorf("%w: the file ends inside a quoted string", ErrTruncated)
+	}
+	return nil, "", 0, fmt.Errorf("%w: the file ends before %s is closed", ErrTruncated, optionSettingsKey)
+}
+
+// newOptionEntry builds an entry from the segment content[segStart:end] with "=" at eqPos.
+func newOptionEntry(content []byte, segStart, eqPos, end int) *optionEntry {
+	if eqPos == -1 {
+		return &optionEntry{name: string(content[segStart:end])}
+	}
+	return &optionEntry{
+		name:     string(content[segStart:eqPos]),
+		hasValue: true,
+		value:    string(content[eqPos+1 : end]),
+	}
+}
+
+// lookup returns the entry for key, or nil when the key is not present.
+func (o *optionSettings) lookup(key string) *optionEntry {
+	for _, entry := range o.entries {
+		if entry.Key() == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Get returns the raw value of key as written in the file.
+func (o *optionSettings) Get(key string) (string, bool) {
+	entry := o.lookup(key)
+	if entry == nil || !entry.hasValue {
+		return "", false
+	}
+	return entry.value, true
+}
+
+// Set replaces the raw value of key and reports whether the key was found.
+func (o *optionSettings) Set(key, rawValue string) bool {
+	entry := o.lookup(key)
+	if entry == nil {
+		return false
+	}
+	entry.hasValue = true
+	entry.value = rawValue
+	return true
+}
+
+// Append adds key with rawValue as the last entry of the struct.
+func (o *optionSettings) Append(key, rawValue string) {
+	o.entries = append(o.entries, &optionEntry{name: key, hasValue: true, value: rawValue})
+}
+
+// Bytes serializes the model back into the full INI content.
+func (o *optionSettings) Bytes() []byte {
+	return o.render(nil)
+}
+
+// render serializes the model, replacing raw values through replace when it is not nil.
+func (o *optionSettings) render(replace func(entry *optionEntry) string) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(o.head) + len(o.foot) + 64*len(o.entries))
+
+	buf.Write(o.head)
+	for i, entry := range o.entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(entry.name)
+		if entry.hasValue {
+			buf.WriteByte('=')
+			if replace != nil {
+				buf.WriteString(replace(entry))
+			} else {
+				buf.WriteString(entry.value)
+			}
+		}
+	}
+	buf.WriteString(o.tail)
+	buf.Write(o.foot)
+	return buf.Bytes()
+}
+
+// quoteString wraps s in quotes, escaping it the way Unreal exports strings in a struct.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteString reverses quoteString. A raw value that is not quoted is returned unchanged.
+func unquoteString(raw string) string {
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return raw
+	}
+	inner := raw[1 : len(raw)-1]
+	if !strings.Contains(inner, `\`) {
+		return inner
+	}
+
+	var b strings.Builder
+	b.Grow(len(inner))
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c != '\\' || i == len(inner)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch inner[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			// \\, \" and \' stand for the character itself
+			b.WriteByte(inner[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,185 @@
+package palconfig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// iniWith returns a settings file whose OptionSettings line has the value line.
+func iniWith(line string) []byte {
+	return []byte("; comment\n" + SectionName + "\nOptionSettings=" + line + "\n")
+}
+
+func TestParseOptionSettingsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		keys []string
+	}{
+		{"plain", `(Difficulty=None,ExpRate=1.000000,ServerName="Default Palworld Server")`, []string{"Difficulty", "ExpRate", "ServerName"}},
+		{"comma in quotes", `(ServerName="a,b",ExpRate=2.000000)`, []string{"ServerName", "ExpRate"}},
+		{"key text in quotes", `(ServerDescription="ExpRate=9,Difficulty=Hard",ExpRate=1.000000)`, []string{"ServerDescription", "ExpRate"}},
+		{"parentheses in quotes", `(ServerName="(a)) (",ExpRate=1.000000)`, []string{"ServerName", "ExpRate"}},
+		{"escaped quote", `(ServerName="say \"hi\", ok",ExpRate=1.000000)`, []string{"ServerName", "ExpRate"}},
+		{"nested struct", `(CrossplayPlatforms=(Steam,Xbox),ExpRate=1.000000)`, []string{"CrossplayPlatforms", "ExpRate"}},
+		{"trailing separator", `(ExpRate=1.000000,)`, []string{"ExpRate"}},
+		{"empty struct", `()`, nil},
+		{"whitespace", `( ExpRate = 1.000000 , bIsPvP=False )`, []string{"ExpRate", "bIsPvP"}},
+		{"bare token", `(ExpRate=1.000000,Flag)`, []string{"ExpRate", "Flag"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := iniWith(tt.line)
+			settings, err := parseOptionSettings(content)
+			if err != nil {
+				t.Fatalf("parseOptionSettings: %v", err)
+			}
+
+			var keys []string
+			for _, entry := range settings.entries {
+				keys = append(keys, entry.Key())
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %q, want %q", keys, tt.keys)
+			}
+			if got := settings.Bytes(); string(got) != string(content) {
+				t.Errorf("round trip changed the content:\ngot  %q\nwant %q", got, content)
+			}
+		})
+	}
+}
+
+func TestParseOptionSettingsValues(t *testing.T) {
+	settings, err := parseOptionSettings(iniWith(`(ServerName="a,b=c)",CrossplayPlatforms=(Steam,Xbox),ExpRate=2.000000)`))
+	if err != nil {
+		t.Fatalf("parseOptionSettings: %v", err)
+	}
+
+	tests := map[string]string{
+		"ServerName":         `"a,b=c)"`,
+		"CrossplayPlatforms": `(Steam,Xbox)`,
+		"ExpRate":            `2.000000`,
+	}
+	for key, want := range tests {
+		if got, ok := settings.Get(key); !ok || got != want {
+			t.Errorf("Get(%s) = %q, %v, want %q", key, got, ok, want)
+		}
+	}
+}
+
+func TestParseOptionSettingsSetKeepsOtherEntries(t *testing.T) {
+	settings, err := parseOptionSettings(iniWith(`( ExpRate = 1.000000 ,ServerName="x",)`))
+	if err != nil {
+		t.Fatalf("parseOptionSettings: %v", err)
+	}
+	settings.Set("ServerName", `"y"`)
+	settings.Append("bIsPvP", "True")
+
+	want := iniWith(`( ExpRate = 1.000000 ,ServerName="y",bIsPvP=True,)`)
+	if got := settings.Bytes(); string(got) != string(want) {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestParseOptionSettingsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    error
+	}{
+		{"empty", "", ErrEmptyConfig},
+		{"missing section", "OptionSettings=(ExpRate=1.000000)\n", ErrMissingSection},
+		{"missing OptionSettings", SectionName + "\n", ErrMissingOptionSettings},
+		{"not a struct", SectionName + "\nOptionSettings=None\n", ErrMissingOptionSettings},
+		{"truncated", SectionName + "\nOptionSettings=(ExpRate=1.000000,Serv", ErrTruncated},
+		{"truncated in quotes", SectionName + "\nOptionSettings=(ServerName=\"abc", ErrTruncated},
+		{"missing closing parenthesis", SectionName + "\nOptionSettings=(ExpRate=1.000000\n", ErrUnbalanced},
+		{"unterminated quote", SectionName + "\nOptionSettings=(ServerName=\"abc)\n", ErrUnbalanced},
+		{"unclosed nested struct", SectionName + "\nOptionSettings=(CrossplayPlatforms=(Steam)\n", ErrUnbalanced},
+		{"text after struct", SectionName + "\nOptionSettings=(ExpRate=1.000000) x\n", ErrUnbalanced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOptionSettings([]byte(tt.content))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("parseOptionSettings error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "Default Palworld Server", `"Default Palworld Server"`},
+		{"empty", "", `""`},
+		{"quotes", `say "hi"`, `"say \"hi\""`},
+		{"backslashes", `C:\Pal\`, `"C:\\Pal\\"`},
+		{"newlines", "one\ntwo\r\n", `"one\ntwo\r\n"`},
+		{"tabs", "a\tb", `"a\tb"`},
+		{"separators", `a,b=(c)`, `"a,b=(c)"`},
+		{"unicode", "Pâl ワールド", `"Pâl ワールド"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quoted := quoteString(tt.value)
+			if quoted != tt.want {
+				t.Errorf("quoteString(%q) = %s, want %s", tt.value, quoted, tt.want)
+			}
+			if got := unquoteString(quoted); got != tt.value {
+				t.Errorf("unquoteString(%s) = %q, want %q", quoted, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestQuoteStringSurvivesOptionSettings(t *testing.T) {
+	values := []string{`say "hi", ok`, `C:\Pal\`, "one\ntwo", "a\tb", `ExpRate=9)`, `\`}
+
+	for _, value := range values {
+		settings, err := parseOptionSettings(iniWith(`(ExpRate=1.000000)`))
+		if err != nil {
+			t.Fatalf("parseOptionSettings: %v", err)
+		}
+		settings.Append("ServerName", quoteString(value))
+
+		reparsed, err := parseOptionSettings(settings.Bytes())
+		if err != nil {
+			t.Fatalf("parseOptionSettings(%q): %v", settings.Bytes(), err)
+		}
+		raw, ok := reparsed.Get("ServerName")
+		if !ok {
+			t.Fatalf("ServerName missing after writing %q", value)
+		}
+		if got := unquoteString(raw); got != value {
+			t.Errorf("round trip of %q = %q", value, got)
+		}
+	}
+}
+
+func TestUnquoteString(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`None`, `None`},
+		{`"`, `"`},
+		{`""`, ``},
+		{`"abc"`, `abc`},
+		{`"a\qb"`, `aqb`},
+		{`"a\"`, `a\`},
+	}
+
+	for _, tt := range tests {
+		if got := unquoteString(tt.raw); got != tt.want {
+			t.Errorf("unquoteString(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
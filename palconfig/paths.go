@@ -0,0 +1,87 @@
+package palconfig
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultsFileName is the settings template the dedicated server ships in its install root
+const DefaultsFileName = "DefaultPalWorldSettings.ini"
+
+// CommonRoots lists the install locations DiscoverRoot looks at, after the working directory.
+var CommonRoots = []string{
+	"steamapps/common/PalServer",                   // SteamCMD below the working or home directory
+	"Steam/steamapps/common/PalServer",             // Steam client library below the working or home directory
+	"/home/steam/Steam/steamapps/common/PalServer", // SteamCMD docker images
+	"/palworld",       // Docker images
+	"/home/container", // Pterodactyl
+}
+
+// Paths holds the files the tool works on.
+type Paths struct {
+	Root     string // server install root
+	Settings string // PalWorldSettings.ini
+	Defaults string // DefaultPalWorldSettings.ini
+}
+
+// ResolvePaths fills in the paths that are empty. An empty root is discovered with
+// DiscoverRoot, and the settings and defaults files are looked up below the root.
+// All returned paths are absolute.
+func ResolvePaths(root, settings, defaults, osFolder string) (Paths, error) {
+	if root == "" {
+		root = DiscoverRoot(osFolder)
+	}
+
+	paths := Paths{Root: root, Settings: settings, Defaults: defaults}
+	if paths.Settings == "" {
+		paths.Settings = SettingsPath(root, osFolder)
+	}
+	if paths.Defaults == "" {
+		paths.Defaults = filepath.Join(root, DefaultsFileName)
+	}
+
+	for _, path := range []*string{&paths.Root, &paths.Settings, &paths.Defaults} {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			return Paths{}, err
+		}
+		*path = abs
+	}
+	return paths, nil
+}
+
+// DiscoverRoot returns the first of the working directory and CommonRoots that
+// looks like a server install. Relative entries of CommonRoots are tried below the
+// working directory and the home directory. When nothing matches it returns ".",
+// so a fresh install is bootstrapped in the working directory.
+func DiscoverRoot(osFolder string) string {
+	candidates := []string{"."}
+	home, _ := os.UserHomeDir()
+	for _, root := range CommonRoots {
+		candidates = append(candidates, root)
+		if !filepath.IsAbs(root) && home != "" {
+			candidates = append(candidates, filepath.Join(home, root))
+		}
+	}
+
+	for _, root := range candidates {
+		if isServerRoot(root, osFolder) {
+			return root
+		}
+	}
+	return "."
+}
+
+// isServerRoot reports whether root holds a settings file, a defaults file or the Pal content folder.
+func isServerRoot(root, osFolder string) bool {
+	for _, path := range []string{
+		SettingsPath(root, osFolder),
+		filepath.Join(root, DefaultsFileName),
+		filepath.Join(root, "Pal"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package palconfig
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrUnsupportedOS is returned when the server platform folder cannot be determined
+var ErrUnsupportedOS = errors.New("unsupported operating system")
+
+// PlatformFolder returns the Config sub folder the dedicated server uses on this system.
+func PlatformFolder() (string, error) {
+	// Determine the operating system
+	switch runtime.GOOS {
+	case "windows":
+		return "WindowsServer", nil
+	case "linux":
+		// Check if the WINEPREFIX environment variable exists
+		if _, winePrefixExists := os.LookupEnv("WINEPREFIX"); winePrefixExists {
+			return "WindowsServer", nil
+		} else if _, err := exec.LookPath("proton"); err == nil {
+			return "WindowsServer", nil
+		}
+		return "LinuxServer", nil
+	default:
+		return "", ErrUnsupportedOS
+	}
+}
+
+// SettingsPath returns the PalWorldSettings.ini path below the server root for the given platform folder.
+func SettingsPath(root, osFolder string) string {
+	return filepath.Join(root, "Pal", "Saved", "Config", osFolder, "PalWorldSettings.ini")
+}
@@ -0,0 +1,134 @@
+package palconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Outcome is what happened to a key while applying the environment.
+type Outcome string
+
+// Outcomes in the order they are summarized.
+const (
+	OutcomeUpdated   Outcome = "updated"
+	OutcomeUnchanged Outcome = "unchanged"
+	OutcomeCleared   Outcome = "cleared"
+	OutcomeSkipped   Outcome = "skipped"   // the environment variable is not set
+	OutcomeRejected  Outcome = "rejected"  // the value failed validation
+	OutcomeNotFound  Outcome = "not_found" // the key is missing from the file and SkipMissing is set
+)
+
+// Outcomes lists every outcome in summary order
+var Outcomes = []Outcome{OutcomeUpdated, OutcomeUnchanged, OutcomeCleared, OutcomeSkipped, OutcomeRejected, OutcomeNotFound}
+
+// KeyResult is the entry of a single key in a Report.
+type KeyResult struct {
+	Key            string  `json:"key"`
+	Env            string  `json:"env"`
+	Outcome        Outcome `json:"outcome"`
+	Source         string  `json:"source"`                    // where the final value came from, see SourceINI
+	RejectedSource string  `json:"rejected_source,omitempty"` // where a rejected or not found value came from
+	Old            string  `json:"old,omitempty"`
+	New            string  `json:"new,omitempty"`
+	Added          bool    `json:"added,omitempty"`   // the key was missing from the file and appended
+	Clamped        bool    `json:"clamped,omitempty"` // the value was clamped to the range of the key
+	Reason         string  `json:"reason,omitempty"`  // why the value was rejected
+}
+
+// Report collects the result of every key, in the order they were processed.
+type Report struct {
+	Path string      `json:"path"`
+	Keys []KeyResult `json:"keys"`
+}
+
+// Add appends the result of a key to the report.
+func (r *Report) Add(result KeyResult) {
+	r.Keys = append(r.Keys, result)
+}
+
+// Count returns the number of keys with outcome.
+func (r *Report) Count(outcome Outcome) int {
+	n := 0
+	for _, result := range r.Keys {
+		if result.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// JSON returns the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Text returns a human readable summary. Unchanged and skipped keys are only counted,
+// every other key is listed.
+func (r *Report) Text() string {
+	var b strings.Builder
+
+	counts := make([]string, len(Outcomes))
+	for i, outcome := range Outcomes {
+		counts[i] = fmt.Sprintf("%d %s", r.Count(outcome), outcome.label())
+	}
+	fmt.Fprintf(&b, "Summary for %s: %s\n", r.Path, strings.Join(counts, ", "))
+
+	// Count the values per source, in the order the sources first appear
+	var sources []string
+	perSource := make(map[string]int)
+	for _, result := range r.Keys {
+		if perSource[result.Source] == 0 {
+			sources = append(sources, result.Source)
+		}
+		perSource[result.Source]++
+	}
+	counts = counts[:0]
+	for _, source := range sources {
+		counts = append(counts, fmt.Sprintf("%d from %s", perSource[source], source))
+	}
+	fmt.Fprintf(&b, "Sources: %s\n", strings.Join(counts, ", "))
+
+	for _, outcome := range Outcomes {
+		if outcome == OutcomeUnchanged || outcome == OutcomeSkipped {
+			continue
+		}
+		for _, result := range r.Keys {
+			if result.Outcome != outcome {
+				continue
+			}
+			fmt.Fprintf(&b, "  %-10s%s", outcome.label(), result.Key)
+			switch outcome {
+			case OutcomeUpdated, OutcomeCleared:
+				old := fmt.Sprintf("%q", result.Old)
+				if result.Added {
+					old = "(missing)"
+				}
+				fmt.Fprintf(&b, ": %s → %q", old, result.New)
+				if result.Clamped {
+					b.WriteString(" (clamped)")
+				}
+			case OutcomeRejected:
+				fmt.Fprintf(&b, ": %q (%s)", result.New, result.Reason)
+			case OutcomeNotFound:
+				fmt.Fprintf(&b, " (%s is set)", result.Env)
+			}
+			if result.RejectedSource != "" {
+				fmt.Fprintf(&b, " from %s, keeping the value from %s", result.RejectedSource, result.Source)
+			} else {
+				fmt.Fprintf(&b, " from %s", result.Source)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// label returns the outcome as written in the text report.
+func (o Outcome) label() string {
+	return strings.ReplaceAll(string(o), "_", " ")
+}
@@ -0,0 +1,42 @@
+package palconfig
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReportSources(t *testing.T) {
+	report := &Report{Path: "PalWorldSettings.ini"}
+	report.Add(KeyResult{Key: "ExpRate", Env: "EXP_RATE", Outcome: OutcomeUpdated, Source: SourceEnvironment, Old: "1.000000", New: "2.000000"})
+	report.Add(KeyResult{Key: "DeathPenalty", Env: "DEATH_PENALTY", Outcome: OutcomeRejected, Source: SourceINI, RejectedSource: "app.env", New: "bogus", Reason: "invalid"})
+	report.Add(KeyResult{Key: "bIsPvP", Env: "IS_PVP", Outcome: OutcomeNotFound, Source: SourceDefaults, RejectedSource: SourceEnvironment})
+	report.Add(KeyResult{Key: "Difficulty", Env: "DIFFICULTY", Outcome: OutcomeSkipped, Source: SourceINI})
+
+	text := report.Text()
+	for _, want := range []string{
+		"Sources: 1 from environment, 2 from ini, 1 from defaults\n",
+		`  updated   ExpRate: "1.000000" → "2.000000" from environment` + "\n",
+		`  rejected  DeathPenalty: "bogus" (invalid) from app.env, keeping the value from ini` + "\n",
+		"  not found bIsPvP (IS_PVP is set) from environment, keeping the value from defaults\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() is missing %q:\n%s", want, text)
+		}
+	}
+
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Keys[1]; got.Source != SourceINI || got.RejectedSource != "app.env" {
+		t.Errorf("JSON sources = %q, %q", got.Source, got.RejectedSource)
+	}
+	if strings.Count(string(data), "rejected_source") != 2 {
+		t.Errorf("rejected_source is not omitted for applied values:\n%s", data)
+	}
+}
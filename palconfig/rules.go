@@ -0,0 +1,198 @@
+package palconfig
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule checks val for the given key. It returns the value in the canonical
+// form that is written to the INI file, or an error explaining the rejection.
+type Rule func(key Key, val string) (string, error)
+
+// ValidationRules holds validation rules for environment variables
+var ValidationRules = map[ValueType]Rule{
+	TypeNumeric: func(key Key, val string) (string, error) {
+		// Numeric: Allows only positive numeric values (e.g., "123", "456")
+		// and normalizes them to plain digits (e.g., "+80" and "0080" become "80")
+		num, err := strconv.Atoi(val)
+		if err != nil || num < 0 {
+			return "", errors.New("must be a positive whole number")
+		}
+		return strconv.Itoa(num), nil
+	},
+	TypeFloating: func(key Key, val string) (string, error) {
+		// Floating: Allows positive numbers in common notations (e.g., "2", "2.5", "2,5", "1e-3")
+		// and normalizes them to the form the game writes (e.g., "2.000000")
+		num, err := parseFloating(val)
+		if err != nil {
+			return "", err
+		}
+		if num < 0 {
+			return "", errors.New("must not be negative")
+		}
+		if num == 0 {
+			num = 0 // "-0" parses as negative zero, which would be written as "-0.000000"
+		}
+		return strconv.FormatFloat(num, 'f', 6, 64), nil
+	},
+	TypeTrueFalse: func(key Key, val string) (string, error) {
+		// TrueFalse: Allows common boolean spellings (e.g., "True", "false", "1", "yes", "off")
+		// and normalizes them to "True" or "False"
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true", "1", "yes", "y", "on":
+			return "True", nil
+		case "false", "0", "no", "n", "off":
+			return "False", nil
+		}
+		return "", errors.New("must be True or False; true/false, 1/0, yes/no and on/off are also accepted")
+	},
+	TypeString: func(key Key, val string) (string, error) {
+		// String: Allows string values with spaces (e.g., "Hello World", "This is a string")
+		return val, nil // No validation needed for string with spaces
+	},
+	TypeAlphaDash: func(key Key, val string) (string, error) {
+		// AlphaDash: Allows only alphanumeric characters and dashes (e.g., "abc123", "test-123")
+		if !alphaDashPattern.MatchString(val) {
+			return "", errors.New("may only contain letters, digits, dashes and underscores")
+		}
+		return val, nil
+	},
+	TypePassword: func(key Key, val string) (string, error) {
+		// Password: Allows any printable ASCII character, including quotes, commas and parentheses (e.g., "p@ss,w\"rd")
+		for _, r := range val {
+			if r < ' ' || r > '~' {
+				return "", errors.New("may only contain printable ASCII characters")
+			}
+		}
+		return val, nil
+	},
+	TypeEnum: func(key Key, val string) (string, error) {
+		// Enum: Allows one of the values listed in the schema, case-insensitive (e.g., "item" becomes "Item")
+		return matchEnum(key.Enum, val)
+	},
+	TypeCrossplayPlatforms: func(key Key, val string) (string, error) {
+		// CrossplayPlatforms: Allows platform lists like "Steam,Xbox,PS5,Mac" or single platforms
+		// Remove any existing parentheses and trim spaces
+		val = strings.Trim(val, "() ")
+		if val == "" {
+			return "", nil // Empty is allowed (means no crossplay)
+		}
+
+		// Split by comma and validate each platform
+		var platforms []string
+		for _, platform := range strings.Split(val, ",") {
+			platform = strings.TrimSpace(platform)
+			if platform == "" {
+				continue
+			}
+			canonical, err := matchEnum(key.Enum, platform)
+			if err != nil {
+				return "", err // Invalid platform found
+			}
+			platforms = append(platforms, canonical)
+		}
+		return strings.Join(platforms, ","), nil
+	},
+	// Add more validation rules as needed
+}
+
+var (
+	alphaDashPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	floatingPattern  = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+)
+
+// parseFloating parses a decimal number written with a point or a single locale comma,
+// with or without a fraction or exponent.
+func parseFloating(val string) (float64, error) {
+	val = strings.TrimSpace(val)
+	if !strings.Contains(val, ".") && strings.Count(val, ",") == 1 {
+		val = strings.Replace(val, ",", ".", 1)
+	}
+	if !floatingPattern.MatchString(val) {
+		return 0, errors.New("must be a number such as 2, 2.5 or 1e-3")
+	}
+	num, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, errors.New("number is out of range")
+	}
+	return num, nil
+}
+
+// matchEnum returns the canonical spelling of val from allowed, ignoring case.
+// When nothing matches, the error suggests the closest allowed value.
+func matchEnum(allowed []string, val string) (string, error) {
+	trimmed := strings.TrimSpace(val)
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, trimmed) {
+			return candidate, nil
+		}
+	}
+
+	msg := fmt.Sprintf("%q is not one of %s", trimmed, strings.Join(allowed, ", "))
+	if suggestion := closestMatch(allowed, trimmed); suggestion != "" {
+		msg += fmt.Sprintf("; did you mean %q?", suggestion)
+	}
+	return "", errors.New(msg)
+}
+
+// closestMatch returns the candidate with the smallest edit distance to val,
+// or "" when no candidate is reasonably close.
+func closestMatch(candidates []string, val string) string {
+	val = strings.ToLower(val)
+	best := ""
+	bestDistance, bestShared := -1, 0
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := levenshtein(lower, val)
+		if strings.HasPrefix(lower, val) || strings.HasPrefix(val, lower) {
+			// A prefix of the right value is almost always a typo of it
+			distance = min(distance, 1)
+		}
+		// On a tie the candidate that shares the longest start with val wins,
+		// so "ItemAnd" suggests ItemAndEquipment rather than Item
+		shared := sharedPrefix(lower, val)
+		if bestDistance == -1 || distance < bestDistance || distance == bestDistance && shared > bestShared {
+			best, bestDistance, bestShared = candidate, distance, shared
+		}
+	}
+
+	// Only suggest when at most a third of the characters differ
+	if bestDistance == -1 || bestDistance > max(2, len(val)/3) {
+		return ""
+	}
+	return best
+}
+
+// sharedPrefix returns the number of leading bytes a and b have in common.
+func sharedPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
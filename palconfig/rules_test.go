@@ -0,0 +1,201 @@
+package palconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchEnum(t *testing.T) {
+	deathPenalty := []string{"None", "Item", "ItemAndEquipment", "All"}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		val     string
+		want    string
+		err     string
+	}{
+		{"exact", deathPenalty, "Item", "Item", ""},
+		{"lower case", deathPenalty, "itemandequipment", "ItemAndEquipment", ""},
+		{"upper case with spaces", deathPenalty, " ALL ", "All", ""},
+		{"typo", deathPenalty, "Itme", "", `"Itme" is not one of None, Item, ItemAndEquipment, All; did you mean "Item"?`},
+		{"typo of a long value", deathPenalty, "ItemAndEquipmnt", "", `did you mean "ItemAndEquipment"?`},
+		{"prefix", deathPenalty, "ItemAnd", "", `did you mean "ItemAndEquipment"?`},
+		{"plural", deathPenalty, "Items", "", `did you mean "Item"?`},
+		{"unrelated", deathPenalty, "Everything", "", `"Everything" is not one of None, Item, ItemAndEquipment, All`},
+		{"short typo", []string{"Text", "Json"}, "jsn", "", `did you mean "Json"?`},
+		{"difficulty", []string{"None", "Casual", "Normal", "Hard"}, "casul", "", `did you mean "Casual"?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchEnum(tt.allowed, tt.val)
+			if tt.err == "" {
+				if err != nil || got != tt.want {
+					t.Errorf("matchEnum(%q) = %q, %v, want %q", tt.val, got, err, tt.want)
+				}
+				return
+			}
+			if err == nil || !strings.HasSuffix(err.Error(), tt.err) {
+				t.Errorf("matchEnum(%q) error = %v, want %q", tt.val, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	platforms := []string{"Steam", "Xbox", "PS5", "Mac"}
+
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"stem", "Steam"},
+		{"xbx", "Xbox"},
+		{"ps4", "PS5"},
+		{"Macintosh", "Mac"},
+		{"Switch", ""},
+		{"Playstation", ""},
+	}
+
+	for _, tt := range tests {
+		if got := closestMatch(platforms, tt.val); got != tt.want {
+			t.Errorf("closestMatch(%q) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"item", "itme", 2},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestEnumRules(t *testing.T) {
+	tests := []struct {
+		key  string
+		val  string
+		want string
+		err  bool
+	}{
+		{"Difficulty", "hard", "Hard", false},
+		{"LogFormatType", "json", "Json", false},
+		{"RandomizerType", "region", "Region", false},
+		{"DeathPenalty", "bogus", "", true},
+		{"CrossplayPlatforms", "steam, xbox", "Steam,Xbox", false},
+		{"CrossplayPlatforms", "(Steam,PS5)", "Steam,PS5", false},
+		{"CrossplayPlatforms", "Steam,,mac,", "Steam,Mac", false},
+		{"CrossplayPlatforms", "", "", false},
+		{"CrossplayPlatforms", "Steam,Switch", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ValidateValue(tt.key, tt.val)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ValidateValue(%s, %q) = %q, %v, want %q", tt.key, tt.val, got, err, tt.want)
+		}
+	}
+}
+
+func TestFloatingRule(t *testing.T) {
+	tests := []struct {
+		val  string
+		want string
+		err  string
+	}{
+		{"2", "2.000000", ""},
+		{"2.", "2.000000", ""},
+		{"2.5", "2.500000", ""},
+		{"2,5", "2.500000", ""},
+		{".5", "0.500000", ""},
+		{"+1.5", "1.500000", ""},
+		{" 3 ", "3.000000", ""},
+		{"1e-3", "0.001000", ""},
+		{"1E3", "1000.000000", ""},
+		{"2,5e1", "25.000000", ""},
+		{"0", "0.000000", ""},
+		{"-0", "0.000000", ""},
+		{"-0.0", "0.000000", ""},
+		{"0.0000001", "0.000000", ""},
+		{"-1", "", "must not be negative"},
+		{"1,000.5", "", "must be a number"},
+		{"1,2,3", "", "must be a number"},
+		{"1.2.3", "", "must be a number"},
+		{"", "", "must be a number"},
+		{"abc", "", "must be a number"},
+		{"0x10", "", "must be a number"},
+		{"NaN", "", "must be a number"},
+		{"Inf", "", "must be a number"},
+		{"1e999", "", "number is out of range"},
+	}
+
+	rule := ValidationRules[TypeFloating]
+	for _, tt := range tests {
+		got, err := rule(Key{}, tt.val)
+		if tt.err == "" {
+			if err != nil || got != tt.want {
+				t.Errorf("Floating(%q) = %q, %v, want %q", tt.val, got, err, tt.want)
+			}
+		} else if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("Floating(%q) = %q, %v, want error %q", tt.val, got, err, tt.err)
+		}
+	}
+}
+
+func TestTrueFalseRule(t *testing.T) {
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"True", "True"},
+		{"true", "True"},
+		{"TRUE", "True"},
+		{"1", "True"},
+		{"yes", "True"},
+		{"Y", "True"},
+		{"on", "True"},
+		{" On ", "True"},
+		{"False", "False"},
+		{"false", "False"},
+		{"0", "False"},
+		{"No", "False"},
+		{"n", "False"},
+		{"OFF", "False"},
+		{"", ""},
+		{"t", ""},
+		{"2", ""},
+		{"enabled", ""},
+		{"yes please", ""},
+	}
+
+	rule := ValidationRules[TypeTrueFalse]
+	for _, tt := range tests {
+		got, err := rule(Key{}, tt.val)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("TrueFalse(%q) = %q, want an error", tt.val, got)
+			}
+		} else if err != nil || got != tt.want {
+			t.Errorf("TrueFalse(%q) = %q, %v, want %q", tt.val, got, err, tt.want)
+		}
+	}
+}
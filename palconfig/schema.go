@@ -0,0 +1,264 @@
+package palconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ValueType names the validation rule a value must satisfy.
+type ValueType string
+
+// Value types used in the schema. Each one has a rule in ValidationRules.
+const (
+	TypeNumeric            ValueType = "Numeric"
+	TypeFloating           ValueType = "Floating"
+	TypeTrueFalse          ValueType = "TrueFalse"
+	TypeString             ValueType = "String"
+	TypeAlphaDash          ValueType = "AlphaDash"
+	TypePassword           ValueType = "Password"
+	TypeEnum               ValueType = "Enum"
+	TypeCrossplayPlatforms ValueType = "CrossplayPlatforms"
+)
+
+// Range is an inclusive interval a numeric value must lie in.
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// Contains reports whether v lies within the range.
+func (r Range) Contains(v float64) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+// Clamp returns v limited to the range.
+func (r Range) Clamp(v float64) float64 {
+	return min(max(v, r.Min), r.Max)
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("%s and %s", formatBound(r.Min), formatBound(r.Max))
+}
+
+// Key describes a single OptionSettings key. Validation, quoting, defaults
+// and documentation are all derived from these fields.
+type Key struct {
+	Name        string    // key name in the OptionSettings line
+	Env         string    // environment variable that sets the key
+	EnvFallback string    // environment variable used when Env is unset or empty
+	Type        ValueType // validation rule for the value
+	Quoted      bool      // whether the value is written between quotes
+	Secret      bool      // whether the value is masked in output
+	Default     string    // value in DefaultPalWorldSettings.ini, without quotes or parentheses
+	Range       *Range    // allowed interval for numeric values, nil when unbounded
+	Enum        []string  // allowed values, empty when any value of Type is accepted
+	Stock       bool      // whether the stock Pterodactyl egg exposes the variable
+	Description string
+}
+
+// rateRange bounds the rate multipliers. The in-game sliders stop at 5 or lower, but the
+// server accepts larger values, so the cap only rejects typos such as 100000.
+var rateRange = &Range{Min: 0, Max: 100}
+
+// Schema lists every key the tool manages, in the order they are processed.
+var Schema = []Key{
+	{Name: "Difficulty", Env: "DIFFICULTY", Type: TypeEnum, Default: "None", Enum: []string{"None", "Casual", "Normal", "Hard"}, Description: "Difficulty preset"},
+	{Name: "DayTimeSpeedRate", Env: "DAY_TIME_SPEED_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Day time speed multiplier"},
+	{Name: "NightTimeSpeedRate", Env: "NIGHT_TIME_SPEED_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Night time speed multiplier"},
+	{Name: "ExpRate", Env: "EXP_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Experience gain multiplier"},
+	{Name: "PalCaptureRate", Env: "PAL_CAPTURE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Pal capture rate multiplier"},
+	{Name: "PalSpawnNumRate", Env: "PAL_SPAWN_NUM_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Pal appearance rate multiplier"},
+	{Name: "PalDamageRateAttack", Env: "PAL_DAMAGE_RATE_ATTACK", Type: TypeFloating, Default: "1.000000", Description: "Multiplier for damage dealt by Pals"},
+	{Name: "PalDamageRateDefense", Env: "PAL_DAMAGE_RATE_DEFENSE", Type: TypeFloating, Default: "1.000000", Description: "Multiplier for damage taken by Pals"},
+	{Name: "PlayerDamageRateAttack", Env: "PLAYER_DAMAGE_RATE_ATTACK", Type: TypeFloating, Default: "1.000000", Description: "Multiplier for damage dealt by players"},
+	{Name: "PlayerDamageRateDefense", Env: "PLAYER_DAMAGE_RATE_DEFENSE", Type: TypeFloating, Default: "1.000000", Description: "Multiplier for damage taken by players"},
+	{Name: "PlayerStomachDecreaceRate", Env: "PLAYER_STOMACH_DECREACE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Player hunger depletion multiplier"},
+	{Name: "PlayerStaminaDecreaceRate", Env: "PLAYER_STAMINA_DECREACE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Player stamina reduction multiplier"},
+	{Name: "PlayerAutoHPRegeneRate", Env: "PLAYER_AUTO_HP_REGENE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Player health regeneration multiplier"},
+	{Name: "PlayerAutoHpRegeneRateInSleep", Env: "PLAYER_AUTO_HP_REGENE_RATE_IN_SLEEP", Type: TypeFloating, Default: "1.000000", Description: "Player health regeneration multiplier while sleeping"},
+	{Name: "PalStomachDecreaceRate", Env: "PAL_STOMACH_DECREACE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Pal hunger depletion multiplier"},
+	{Name: "PalStaminaDecreaceRate", Env: "PAL_STAMINA_DECREACE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Pal stamina reduction multiplier"},
+	{Name: "PalAutoHPRegeneRate", Env: "PAL_AUTO_HP_REGENE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Pal health regeneration multiplier"},
+	{Name: "PalAutoHpRegeneRateInSleep", Env: "PAL_AUTO_HP_REGENE_RATE_IN_SLEEP", Type: TypeFloating, Default: "1.000000", Description: "Pal health regeneration multiplier while in the Palbox"},
+	{Name: "BuildObjectDamageRate", Env: "BUILD_OBJECT_DAMAGE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Multiplier for damage dealt to structures"},
+	{Name: "BuildObjectDeteriorationDamageRate", Env: "BUILD_OBJECT_DETERIORATION_DAMAGE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Structure deterioration multiplier"},
+	{Name: "CollectionDropRate", Env: "COLLECTION_DROP_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Gatherable item drop multiplier"},
+	{Name: "CollectionObjectHpRate", Env: "COLLECTION_OBJECT_HP_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Gatherable object health multiplier"},
+	{Name: "CollectionObjectRespawnSpeedRate", Env: "COLLECTION_OBJECT_RESPAWN_SPEED_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Gatherable object respawn interval multiplier"},
+	{Name: "EnemyDropItemRate", Env: "ENEMY_DROP_ITEM_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Enemy item drop multiplier"},
+	{Name: "DeathPenalty", Env: "DEATH_PENALTY", Type: TypeEnum, Default: "All", Enum: []string{"None", "Item", "ItemAndEquipment", "All"}, Description: "Items lost when a player dies"},
+	{Name: "bEnablePlayerToPlayerDamage", Env: "ENABLE_PLAYER_TO_PLAYER_DAMAGE", Type: TypeTrueFalse, Default: "False", Description: "Allow players to damage each other"},
+	{Name: "bEnableFriendlyFire", Env: "ENABLE_FRIENDLY_FIRE", Type: TypeTrueFalse, Default: "False", Description: "Allow damage between guild members"},
+	{Name: "bEnableInvaderEnemy", Env: "ENABLE_ENEMY", Type: TypeTrueFalse, Default: "True", Stock: true, Description: "Enable raid events"},
+	{Name: "bActiveUNKO", Env: "ACTIVE_UNKO", Type: TypeTrueFalse, Default: "False", Description: "Enable Pal droppings"},
+	{Name: "bEnableAimAssistPad", Env: "ENABLE_AIM_ASSIST_PAD", Type: TypeTrueFalse, Default: "True", Description: "Enable aim assist for controllers"},
+	{Name: "bEnableAimAssistKeyboard", Env: "ENABLE_AIM_ASSIST_KEYBOARD", Type: TypeTrueFalse, Default: "False", Description: "Enable aim assist for keyboard and mouse"},
+	{Name: "DropItemMaxNum", Env: "DROP_ITEM_MAX_NUM", Type: TypeNumeric, Default: "3000", Range: &Range{Min: 0, Max: 5000}, Description: "Maximum number of dropped items in the world"},
+	{Name: "DropItemMaxNum_UNKO", Env: "DROP_ITEM_MAX_NUM_UNKO", Type: TypeNumeric, Default: "100", Description: "Maximum number of Pal droppings in the world"},
+	{Name: "BaseCampMaxNum", Env: "BASE_CAMP_MAX_NUM", Type: TypeNumeric, Default: "128", Range: &Range{Min: 1, Max: 500}, Description: "Maximum number of bases on the server"},
+	{Name: "BaseCampWorkerMaxNum", Env: "BASE_CAMP_WORKER_MAX_NUM", Type: TypeNumeric, Default: "15", Range: &Range{Min: 1, Max: 50}, Description: "Maximum number of working Pals per base"},
+	{Name: "DropItemAliveMaxHours", Env: "DROP_ITEM_ALIVE_MAX_HOURS", Type: TypeFloating, Default: "1.000000", Description: "Hours before dropped items despawn"},
+	{Name: "bAutoResetGuildNoOnlinePlayers", Env: "AUTO_RESET_GUILD_NO_ONLINE_PLAYERS", Type: TypeTrueFalse, Default: "False", Description: "Reset guilds that have no online players"},
+	{Name: "AutoResetGuildTimeNoOnlinePlayers", Env: "AUTO_RESET_GUILD_TIME_NO_ONLINE_PLAYERS", Type: TypeFloating, Default: "72.000000", Description: "Hours without online players before a guild is reset"},
+	{Name: "GuildPlayerMaxNum", Env: "GUILD_PLAYER_MAX_NUM", Type: TypeNumeric, Default: "20", Range: &Range{Min: 1, Max: 100}, Description: "Maximum number of players per guild"},
+	{Name: "BaseCampMaxNumInGuild", Env: "BASE_CAMP_MAX_NUM_IN_GUILD", Type: TypeNumeric, Default: "4", Range: &Range{Min: 1, Max: 10}, Description: "Maximum number of bases per guild"},
+	{Name: "PalEggDefaultHatchingTime", Env: "PAL_EGG_DEFAULT_HATCHING_TIME", Type: TypeFloating, Default: "72.000000", Description: "Hours needed to hatch a huge egg"},
+	{Name: "WorkSpeedRate", Env: "WORK_SPEED_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Pal work speed multiplier"},
+	{Name: "bIsMultiplay", Env: "IS_MULTIPLAY", Type: TypeTrueFalse, Default: "False", Description: "Enable multiplayer"},
+	{Name: "bIsPvP", Env: "IS_PVP", Type: TypeTrueFalse, Default: "False", Description: "Enable PvP"},
+	{Name: "bCanPickupOtherGuildDeathPenaltyDrop", Env: "CAN_PICKUP_OTHER_GUILD_DEATH_PENALTY_DROP", Type: TypeTrueFalse, Default: "False", Description: "Allow picking up items dropped by players of other guilds"},
+	{Name: "bEnableNonLoginPenalty", Env: "ENABLE_NON_LOGIN_PENALTY", Type: TypeTrueFalse, Default: "True", Description: "Enable the penalty for not logging in"},
+	{Name: "bEnableFastTravel", Env: "ENABLE_FAST_TRAVEL", Type: TypeTrueFalse, Default: "True", Description: "Enable fast travel"},
+	{Name: "bIsStartLocationSelectByMap", Env: "IS_START_LOCATION_SELECT_BY_MAP", Type: TypeTrueFalse, Default: "True", Description: "Let players pick their start location on the map"},
+	{Name: "bExistPlayerAfterLogout", Env: "EXIST_PLAYER_AFTER_LOGOUT", Type: TypeTrueFalse, Default: "False", Description: "Keep player characters in the world after logout"},
+	{Name: "bEnableDefenseOtherGuildPlayer", Env: "ENABLE_DEFENSE_OTHER_GUILD_PLAYER", Type: TypeTrueFalse, Default: "False", Description: "Allow defending against players of other guilds"},
+	{Name: "CoopPlayerMaxNum", Env: "COOP_PLAYER_MAX_NUM", Type: TypeNumeric, Default: "4", Range: &Range{Min: 1, Max: 32}, Description: "Maximum number of players in a co-op session"},
+	{Name: "ServerPlayerMaxNum", Env: "MAX_PLAYERS", Type: TypeNumeric, Default: "32", Range: &Range{Min: 1, Max: 32}, Stock: true, Description: "Maximum number of players on the server"},
+	{Name: "ServerName", Env: "SERVER_NAME", Type: TypeString, Quoted: true, Default: "Default Palworld Server", Stock: true, Description: "Server name shown in the server list"},
+	{Name: "ServerDescription", Env: "SERVER_DESCRIPTION", Type: TypeString, Quoted: true, Default: "", Stock: true, Description: "Server description shown in the server list"},
+	{Name: "ServerPassword", Env: "SERVER_PASSWORD", Type: TypePassword, Quoted: true, Secret: true, Default: "", Stock: true, Description: "Password required to join the server"},
+	{Name: "AdminPassword", Env: "ADMIN_PASSWORD", Type: TypePassword, Quoted: true, Secret: true, Default: "", Stock: true, Description: "Password for admin commands and RCON"},
+	{Name: "PublicIP", Env: "PUBLIC_IP", EnvFallback: "SERVER_IP", Type: TypeString, Quoted: true, Default: "", Stock: true, Description: "Public IP address announced to the community server list"},
+	{Name: "PublicPort", Env: "SERVER_PORT", Type: TypeNumeric, Default: "8211", Range: &Range{Min: 1, Max: 65535}, Stock: true, Description: "Public port announced to the community server list"},
+	{Name: "RCONPort", Env: "RCON_PORT", Type: TypeNumeric, Default: "25575", Range: &Range{Min: 1, Max: 65535}, Stock: true, Description: "RCON port"},
+	{Name: "RCONEnabled", Env: "RCON_ENABLE", Type: TypeTrueFalse, Default: "False", Stock: true, Description: "Enable RCON"},
+	{Name: "bUseAuth", Env: "USE_AUTH", Type: TypeTrueFalse, Default: "True", Description: "Enable authentication"},
+	{Name: "BanListURL", Env: "BAN_LIST_URL", Type: TypeString, Quoted: true, Default: "https://api.palworldgame.com/api/banlist.txt", Description: "URL of the ban list"},
+	{Name: "Region", Env: "SERVER_REGION", Type: TypeString, Quoted: true, Default: "", Description: "Server region"},
+	{Name: "bShowPlayerList", Env: "SHOW_PLAYER_LIST", Type: TypeTrueFalse, Default: "False", Description: "Show the player list in the ESC menu"},
+	{Name: "RESTAPIEnabled", Env: "REST_API_ENABLED", Type: TypeTrueFalse, Default: "False", Description: "Enable the REST API"},
+	{Name: "RESTAPIPort", Env: "REST_API_PORT", Type: TypeNumeric, Default: "8212", Range: &Range{Min: 1, Max: 65535}, Description: "REST API port"},
+	{Name: "bIsUseBackupSaveData", Env: "USE_BACKUP_SAVE_DATA", Type: TypeTrueFalse, Default: "True", Description: "Enable world backups"},
+	{Name: "LogFormatType", Env: "LOG_FORMAT_TYPE", Type: TypeEnum, Default: "Text", Enum: []string{"Text", "Json"}, Description: "Format of the server log"},
+	{Name: "SupplyDropSpan", Env: "SUPPLY_DROP_SPAN", Type: TypeNumeric, Default: "180", Description: "Minutes between supply drops"},
+	{Name: "ChatPostLimitPerMinute", Env: "CHAT_POST_LIMIT", Type: TypeNumeric, Default: "10", Description: "Maximum chat messages per player per minute"},
+	{Name: "bInvisibleOtherGuildBaseCampAreaFX", Env: "INVISIBLE_OTHER_GUILD_BASE", Type: TypeTrueFalse, Default: "False", Description: "Hide the base area effect of other guilds"},
+	{Name: "AutoSaveSpan", Env: "AUTO_SAVE_SPAN", Type: TypeFloating, Default: "30.000000", Description: "Seconds between auto saves"},
+	{Name: "RandomizerType", Env: "RANDOMIZER_TYPE", Type: TypeEnum, Default: "None", Enum: []string{"None", "Region", "All"}, Description: "Pal spawn randomizer mode"},
+	{Name: "RandomizerSeed", Env: "RANDOMIZER_SEED", Type: TypeString, Quoted: true, Default: "", Description: "Seed for the Pal spawn randomizer"},
+	{Name: "BuildObjectHpRate", Env: "BUILD_OBJECT_HP_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Structure health multiplier"},
+	{Name: "bHardcore", Env: "HARDCORE", Type: TypeTrueFalse, Default: "False", Description: "Enable hardcore mode"},
+	{Name: "bPalLost", Env: "PAL_LOST", Type: TypeTrueFalse, Default: "False", Description: "Lose Pals permanently when they die"},
+	{Name: "bBuildAreaLimit", Env: "BUILD_AREA_LIMIT", Type: TypeTrueFalse, Default: "False", Description: "Prevent building near fast travel points and other landmarks"},
+	{Name: "ItemWeightRate", Env: "ITEM_WEIGHT_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Item weight multiplier"},
+	{Name: "EnablePredatorBossPal", Env: "ENABLE_PREDATOR_BOSS_PAL", Type: TypeTrueFalse, Default: "True", Description: "Spawn predator Pals"},
+	{Name: "MaxBuildingLimitNum", Env: "MAX_BUILDING_LIMIT_NUM", Type: TypeNumeric, Default: "0", Description: "Maximum number of structures per guild, 0 is unlimited"},
+	{Name: "ServerReplicatePawnCullDistance", Env: "SERVER_REPLICATE_PAWN_CULL_DISTANCE", Type: TypeFloating, Default: "15000.000000", Description: "Distance in centimeters at which Pals are synced to players"},
+	{Name: "bIsRandomizerPalLevelRandom", Env: "IS_RANDOMIZER_PAL_LEVEL_RANDOM", Type: TypeTrueFalse, Default: "False", Description: "Randomize Pal levels when the randomizer is enabled"},
+	{Name: "bAllowGlobalPalboxExport", Env: "ALLOW_GLOBAL_PALBOX_EXPORT", Type: TypeTrueFalse, Default: "True", Description: "Allow exporting Pals to the global Palbox"},
+	{Name: "bAllowGlobalPalboxImport", Env: "ALLOW_GLOBAL_PALBOX_IMPORT", Type: TypeTrueFalse, Default: "False", Description: "Allow importing Pals from the global Palbox"},
+	{Name: "bCharacterRecreateInHardcore", Env: "CHARACTER_RECREATE_IN_HARDCORE", Type: TypeTrueFalse, Default: "False", Description: "Allow creating a new character after dying in hardcore mode"},
+	{Name: "EquipmentDurabilityDamageRate", Env: "EQUIPMENT_DURABILITY_DAMAGE_RATE", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Equipment durability loss multiplier"},
+	{Name: "ItemContainerForceMarkDirtyInterval", Env: "ITEM_CONTAINER_FORCE_MARK_DIRTY_INTERVAL", Type: TypeFloating, Default: "1.000000", Description: "Seconds between forced container syncs"},
+	{Name: "ItemCorruptionMultiplier", Env: "ITEM_CORRUPTION_MULTIPLIER", Type: TypeFloating, Default: "1.000000", Range: rateRange, Description: "Item decay multiplier"},
+	{Name: "CrossplayPlatforms", Env: "CROSSPLAY_PLATFORMS", Type: TypeCrossplayPlatforms, Default: "Steam,Xbox,PS5,Mac", Enum: []string{"Steam", "Xbox", "PS5", "Mac"}, Description: "Platforms that are allowed to join"},
+}
+
+// schemaIndex maps key names to their position in Schema
+var schemaIndex = func() map[string]int {
+	index := make(map[string]int, len(Schema))
+	for i, key := range Schema {
+		index[key.Name] = i
+	}
+	return index
+}()
+
+// LookupKey returns the schema entry for name.
+func LookupKey(name string) (Key, bool) {
+	i, ok := schemaIndex[name]
+	if !ok {
+		return Key{}, false
+	}
+	return Schema[i], true
+}
+
+// IsKnownKey reports whether key is an OptionSettings key handled by this package.
+func IsKnownKey(key string) bool {
+	_, ok := schemaIndex[key]
+	return ok
+}
+
+// RedactedValue replaces the value of secret keys in output
+const RedactedValue = "********"
+
+// Redact returns value, or RedactedValue when key is secret and value is not empty.
+func Redact(key, value string) string {
+	if spec, ok := LookupKey(key); ok && spec.Secret && value != "" {
+		return RedactedValue
+	}
+	return value
+}
+
+// EnvName returns the environment variable that sets the key, taking EnvFallback into account.
+func (k Key) EnvName() string {
+	if k.EnvFallback == "" {
+		return k.Env
+	}
+	// Use Env if it exists and is not empty, fall back otherwise
+	if val, ok := os.LookupEnv(k.Env); ok && val != "" {
+		return k.Env
+	}
+	return k.EnvFallback
+}
+
+// EnvVars returns the OptionSettings keys mapped to the environment variables that set them.
+func EnvVars() map[string]string {
+	vars := make(map[string]string, len(Schema))
+	for _, key := range Schema {
+		vars[key.Name] = key.EnvName()
+	}
+	return vars
+}
+
+// MarkdownTable renders the schema as the key table used in the README.
+func MarkdownTable() string {
+	var b strings.Builder
+	b.WriteString("| Config Keyword | ENV Variable | Type | Default | Description | Pterodactyl Stock |\n")
+	b.WriteString("|----------------|--------------|------|---------|-------------|-------------------|\n")
+	for _, key := range Schema {
+		env := key.Env
+		if key.EnvFallback != "" {
+			env = fmt.Sprintf("%s / %s", key.Env, key.EnvFallback)
+		}
+		stock := ""
+		if key.Stock {
+			stock = "✅"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			key.Name, env, key.Type, markdownCode(key.Default), key.describe(), stock)
+	}
+	return b.String()
+}
+
+// describe returns the description with the allowed values or range appended.
+func (k Key) describe() string {
+	desc := k.Description
+	if len(k.Enum) > 0 {
+		desc += fmt.Sprintf(" (%s)", strings.Join(k.Enum, ", "))
+	}
+	if k.Range != nil {
+		desc += fmt.Sprintf(" (%s to %s)", formatBound(k.Range.Min), formatBound(k.Range.Max))
+	}
+	return desc
+}
+
+// formatNumber formats v the way the game writes values of the key's type.
+func (k Key) formatNumber(v float64) string {
+	if k.Type == TypeNumeric {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
+
+func formatBound(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%f", v), "0"), ".")
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
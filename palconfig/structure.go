@@ -0,0 +1,135 @@
+package palconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SectionName is the INI section the server reads OptionSettings from
+const SectionName = "[/Script/Pal.PalGameWorldSettings]"
+
+// Reasons a PalWorldSettings.ini can not be used. They are wrapped with the details.
+var (
+	ErrEmptyConfig           = errors.New("file is empty")
+	ErrMissingSection        = fmt.Errorf("section %s not found", SectionName)
+	ErrMissingOptionSettings = fmt.Errorf("%s line not found in section %s", optionSettingsKey, SectionName)
+	ErrTruncated             = errors.New("file is truncated")
+	ErrUnbalanced            = errors.New("unbalanced quotes or parentheses")
+)
+
+// ErrDuplicate is wrapped by the warnings of CheckStructure about repeated content
+var ErrDuplicate = errors.New("duplicated content")
+
+// optionSettingsLine is an OptionSettings line inside the settings section
+type optionSettingsLine struct {
+	line  int // 1-based line number
+	start int // offset just past the opening "(", or -1 when the value is not a struct
+}
+
+// iniLayout is the line structure of a settings file that matters to the server
+type iniLayout struct {
+	sectionLines []int // line numbers of every SectionName header
+	options      []optionSettingsLine
+}
+
+// scanLayout finds the settings section headers and the OptionSettings lines below them.
+func scanLayout(content []byte) iniLayout {
+	var layout iniLayout
+	inSection := false
+
+	offset := 0
+	for lineNumber := 1; offset <= len(content); lineNumber++ {
+		lineEnd := bytes.IndexByte(content[offset:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += offset
+		}
+
+		line := bytes.TrimSpace(content[offset:lineEnd])
+		switch {
+		case len(line) > 0 && line[0] == '[' && line[len(line)-1] == ']':
+			inSection = strings.EqualFold(string(line), SectionName)
+			if inSection {
+				layout.sectionLines = append(layout.sectionLines, lineNumber)
+			}
+		case inSection && bytes.HasPrefix(line, []byte(optionSettingsKey)):
+			rest := bytes.TrimLeft(line[len(optionSettingsKey):], " \t")
+			if len(rest) == 0 || rest[0] != '=' {
+				break
+			}
+			option := optionSettingsLine{line: lineNumber, start: -1}
+			if value := bytes.TrimLeft(rest[1:], " \t"); len(value) > 0 && value[0] == '(' {
+				// Offset of the value within the untrimmed line
+				option.start = offset + bytes.IndexByte(content[offset:lineEnd], '(') + 1
+			}
+			layout.options = append(layout.options, option)
+		}
+
+		offset = lineEnd + 1
+	}
+	return layout
+}
+
+// lineOf returns the 1-based line number of offset in content.
+func lineOf(content []byte, offset int) int {
+	return bytes.Count(content[:min(offset, len(content))], []byte("\n")) + 1
+}
+
+// CheckStructure reports whether content is a usable PalWorldSettings.ini: it needs
+// the settings section with a parseable OptionSettings line. The returned error wraps
+// one of ErrEmptyConfig, ErrMissingSection, ErrMissingOptionSettings, ErrTruncated or
+// ErrUnbalanced, or ErrUnsupportedEncoding. Repeated sections, lines or keys do not
+// make the file unusable and are returned as warnings wrapping ErrDuplicate.
+func CheckStructure(content []byte) ([]error, error) {
+	content, _, err := Decode(content)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := parseOptionSettings(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []error
+	layout := scanLayout(content)
+	if len(layout.sectionLines) > 1 {
+		warnings = append(warnings, fmt.Errorf("%w: section %s appears %d times, on lines %s",
+			ErrDuplicate, SectionName, len(layout.sectionLines), joinInts(layout.sectionLines)))
+	}
+	if len(layout.options) > 1 {
+		lines := make([]int, len(layout.options))
+		for i, option := range layout.options {
+			lines[i] = option.line
+		}
+		warnings = append(warnings, fmt.Errorf("%w: %s appears %d times, on lines %s, only line %d is used",
+			ErrDuplicate, optionSettingsKey, len(lines), joinInts(lines), lines[0]))
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, entry := range settings.entries {
+		key := entry.Key()
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	for _, key := range order {
+		if counts[key] > 1 {
+			warnings = append(warnings, fmt.Errorf("%w: key %s appears %d times in %s, only the first is used",
+				ErrDuplicate, key, counts[key], optionSettingsKey))
+		}
+	}
+	return warnings, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,80 @@
+package palconfig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckStructure(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		warnings []string
+		err      error
+	}{
+		{"valid", SectionName + "\nOptionSettings=(ExpRate=1.000000)\n", nil, nil},
+		{"default template", string(DefaultTemplate()), nil, nil},
+		{"section in another case", "[/script/pal.palgameworldsettings]\nOptionSettings=(ExpRate=1.000000)\n", nil, nil},
+		{"other sections", "[Other]\nOptionSettings=(x)\n" + SectionName + "\nOptionSettings=(ExpRate=1.000000)\n[More]\nOptionSettings=(y)\n", nil, nil},
+		{
+			"duplicated key",
+			SectionName + "\nOptionSettings=(ExpRate=1.000000,bIsPvP=False,ExpRate=2.000000,bIsPvP=True,ExpRate=3.000000)\n",
+			[]string{
+				"duplicated content: key ExpRate appears 3 times in OptionSettings, only the first is used",
+				"duplicated content: key bIsPvP appears 2 times in OptionSettings, only the first is used",
+			},
+			nil,
+		},
+		{
+			"duplicated OptionSettings line",
+			SectionName + "\nOptionSettings=(ExpRate=1.000000)\n; comment\nOptionSettings=(ExpRate=2.000000)\n",
+			[]string{"duplicated content: OptionSettings appears 2 times, on lines 2, 4, only line 2 is used"},
+			nil,
+		},
+		{
+			"duplicated section",
+			SectionName + "\nOptionSettings=(ExpRate=1.000000)\n" + SectionName + "\n",
+			[]string{"duplicated content: section " + SectionName + " appears 2 times, on lines 1, 3"},
+			nil,
+		},
+		{"empty", "", nil, ErrEmptyConfig},
+		{"blank", " \n\n", nil, ErrEmptyConfig},
+		{"missing section", "[Other]\nOptionSettings=(ExpRate=1.000000)\n", nil, ErrMissingSection},
+		{"missing OptionSettings", SectionName + "\n", nil, ErrMissingOptionSettings},
+		{"truncated", SectionName + "\nOptionSettings=(ExpRate=1.0", nil, ErrTruncated},
+		{"unbalanced", SectionName + "\nOptionSettings=(ExpRate=1.000000\n", nil, ErrUnbalanced},
+		{"UTF-16BE", "\xFE\xFF\x00[", nil, ErrUnsupportedEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := CheckStructure([]byte(tt.content))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CheckStructure error = %v, want %v", err, tt.err)
+			}
+
+			var got []string
+			for _, warning := range warnings {
+				if !errors.Is(warning, ErrDuplicate) {
+					t.Errorf("warning %q does not wrap ErrDuplicate", warning)
+				}
+				got = append(got, warning.Error())
+			}
+			if !reflect.DeepEqual(got, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", got, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestCheckStructureUTF16(t *testing.T) {
+	content := append(append([]byte{}, bomUTF16LE...), utf16le(SectionName+"\r\nOptionSettings=(ExpRate=1.000000,ExpRate=2.000000)\r\n")...)
+	warnings, err := CheckStructure(content)
+	if err != nil {
+		t.Fatalf("CheckStructure: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q, want the duplicated ExpRate", warnings)
+	}
+}
@@ -0,0 +1,17 @@
+package palconfig
+
+import _ "embed"
+
+// DefaultTemplateOrigin describes where the embedded template comes from. It is not the
+// stock file of the game: it holds the defaults of the keys in Schema and nothing else,
+// so the server uses its built-in defaults for every other setting.
+const DefaultTemplateOrigin = "generated from the key schema"
+
+//go:embed DefaultPalWorldSettings.ini
+var defaultTemplate []byte
+
+// DefaultTemplate returns a copy of the embedded DefaultPalWorldSettings.ini,
+// used when the server install does not provide one.
+func DefaultTemplate() []byte {
+	return append([]byte(nil), defaultTemplate...)
+}